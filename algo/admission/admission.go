@@ -0,0 +1,136 @@
+package admission
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Admittable adalah simulator yang bisa dibungkus admission policy.
+type Admittable interface {
+	simulator.Simulator
+	Contains(address int) bool
+	Victim() (address int, ok bool)
+}
+
+type Admission struct {
+	name      string
+	cacheSize int
+	cache     Admittable
+	policy    Policy
+
+	totalaccess int
+	hit         int
+	miss        int
+	admitted    int
+	bypassed    int
+	write       int
+
+	simulator.Emitter
+}
+
+func NewAdmission(name string, cacheSize int, cache Admittable, policy Policy) *Admission {
+	return &Admission{
+		name:      name,
+		cacheSize: cacheSize,
+		cache:     cache,
+		policy:    policy,
+	}
+}
+
+func (adm *Admission) Get(trace simulator.Trace) (err error) {
+	adm.totalaccess++
+
+	if adm.cache.Contains(trace.Addr) {
+		adm.hit++
+		if trace.Op == "W" {
+			adm.write++
+		}
+		adm.policy.Record(trace.Addr)
+		return adm.cache.Get(trace)
+	}
+
+	adm.miss++
+	victim, evicting := adm.cache.Victim()
+	if adm.policy.Admit(trace.Addr, victim, evicting) {
+		// blok ditulis ke SSD oleh policy di bawahnya
+		adm.admitted++
+		adm.write++
+		err = adm.cache.Get(trace)
+	} else {
+		// dilayani langsung dari HDD tanpa menyentuh cache
+		adm.bypassed++
+		adm.Emit(simulator.EventMiss, trace.Addr, simulator.TierHDD, adm.totalaccess)
+	}
+	adm.policy.Record(trace.Addr)
+
+	return err
+}
+
+// SetObserver meneruskan observer ke cache di bawahnya dengan nomor
+// request milik Admission, karena request yang di-bypass tidak sampai ke
+// cache tersebut
+func (adm *Admission) SetObserver(observer simulator.Observer) {
+	adm.Emitter.SetObserver(observer)
+	observable, ok := adm.cache.(simulator.Observable)
+	if !ok {
+		return
+	}
+	if observer == nil {
+		observable.SetObserver(nil)
+		return
+	}
+	observable.SetObserver(simulator.ObserverFunc(func(event simulator.Event) {
+		event.Request = adm.totalaccess
+		observer.Observe(event)
+	}))
+}
+
+func (adm *Admission) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	writeEfficiency := float64(0)
+	if adm.write > 0 {
+		writeEfficiency = float64(adm.hit) / float64(adm.write)
+	}
+	file.WriteString("------------------------------------\n")
+	file.WriteString(fmt.Sprintf("%s + admission %s\n", adm.name, adm.policy.Name()))
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", adm.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", adm.cacheSize))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", adm.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", adm.miss))
+	file.WriteString(fmt.Sprintf("admitted: %d\n", adm.admitted))
+	file.WriteString(fmt.Sprintf("bypassed: %d\n", adm.bypassed))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", adm.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %8.4f\n", writeEfficiency))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(adm.hit)/float64(adm.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!%s|%d|%d|%d\n", adm.name, adm.cacheSize, adm.hit, adm.write))
+	return err
+}
+
+func (adm *Admission) Misses() int {
+	return adm.miss
+}
+
+func (adm *Admission) Capacity() int {
+	return adm.cacheSize
+}
+
+func (adm *Admission) Metadata() (metadata simulator.Metadata) {
+	if reporter, ok := adm.cache.(simulator.MetadataReporter); ok {
+		metadata = reporter.Metadata()
+	}
+	if reporter, ok := adm.policy.(simulator.MetadataReporter); ok {
+		policy := reporter.Metadata()
+		metadata.Tracked += policy.Tracked
+		metadata.Ghost += policy.Ghost
+		metadata.FixedBytes += policy.FixedBytes
+		if metadata.BytesPerEntry == 0 {
+			metadata.BytesPerEntry = policy.BytesPerEntry
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,208 @@
+package admission
+
+import (
+	"fmt"
+	"math/rand"
+	"unsafe"
+
+	"ixtza/ajk/wec/algo/internal/sketch"
+	"ixtza/ajk/wec/simulator"
+)
+
+// Policy memutuskan apakah blok yang miss boleh ditulis ke SSD.
+// Admit dipanggil sebelum Record untuk akses yang sama, sehingga
+// policy hanya melihat riwayat akses sebelumnya.
+type Policy interface {
+	Name() string
+	Admit(address, victim int, evicting bool) bool
+	Record(address int)
+}
+
+type (
+	Always struct{}
+
+	NHit struct {
+		n      int
+		window int
+		counts map[int]int
+		ring   []int
+		head   int
+	}
+
+	Bloom struct {
+		capacity int
+		filter   *sketch.Bloom
+	}
+
+	Probabilistic struct {
+		probability float64
+		rand        *rand.Rand
+	}
+
+	TinyLFU struct {
+		sketch     *sketch.CountMin
+		doorkeeper *sketch.Bloom
+	}
+)
+
+func NewAlways() *Always {
+	return &Always{}
+}
+
+func (a *Always) Name() string                                  { return "always" }
+func (a *Always) Admit(address, victim int, evicting bool) bool { return true }
+func (a *Always) Record(address int)                            {}
+
+// NewNHit menerima blok setelah n akses dalam window request terakhir.
+// window 0 berarti riwayat tidak pernah dilupakan.
+func NewNHit(n, window int) *NHit {
+	if n < 1 {
+		n = 1
+	}
+	nhit := &NHit{
+		n:      n,
+		window: window,
+		counts: map[int]int{},
+	}
+	if window > 0 {
+		nhit.ring = make([]int, 0, window)
+	}
+	return nhit
+}
+
+func NewSecondHit(window int) *NHit {
+	return NewNHit(2, window)
+}
+
+func (nhit *NHit) Name() string {
+	if nhit.window > 0 {
+		return fmt.Sprintf("%d-hit/%d", nhit.n, nhit.window)
+	}
+	return fmt.Sprintf("%d-hit", nhit.n)
+}
+
+func (nhit *NHit) Admit(address, victim int, evicting bool) bool {
+	return nhit.counts[address]+1 >= nhit.n
+}
+
+func (nhit *NHit) Record(address int) {
+	nhit.counts[address]++
+	if nhit.window <= 0 {
+		return
+	}
+	if len(nhit.ring) < nhit.window {
+		nhit.ring = append(nhit.ring, address)
+		return
+	}
+	// buang akses paling lama dari window
+	old := nhit.ring[nhit.head]
+	nhit.ring[nhit.head] = address
+	nhit.head = (nhit.head + 1) % nhit.window
+	if nhit.counts[old] <= 1 {
+		delete(nhit.counts, old)
+	} else {
+		nhit.counts[old]--
+	}
+}
+
+// NewBloom menerima blok yang sudah pernah terlihat. Filter dikosongkan
+// setiap capacity blok unik supaya riwayat lama terlupakan.
+func NewBloom(capacity int) *Bloom {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Bloom{
+		capacity: capacity,
+		filter:   sketch.NewBloom(capacity*10, 7),
+	}
+}
+
+func (b *Bloom) Name() string { return "bloom" }
+
+func (b *Bloom) Admit(address, victim int, evicting bool) bool {
+	return b.filter.Contains(address)
+}
+
+func (b *Bloom) Record(address int) {
+	b.filter.Add(address)
+	if b.filter.Len() >= b.capacity {
+		b.filter.Reset()
+	}
+}
+
+func NewProbabilistic(probability float64, seed int64) *Probabilistic {
+	return &Probabilistic{
+		probability: probability,
+		rand:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (p *Probabilistic) Name() string {
+	return fmt.Sprintf("probabilistic(%v)", p.probability)
+}
+
+func (p *Probabilistic) Admit(address, victim int, evicting bool) bool {
+	return p.rand.Float64() < p.probability
+}
+
+func (p *Probabilistic) Record(address int) {}
+
+// NewTinyLFU membandingkan estimasi frekuensi kandidat dengan korban
+// menggunakan count-min sketch yang dibagi dua setiap sampleSize akses.
+func NewTinyLFU(cacheSize int) *TinyLFU {
+	if cacheSize < 1 {
+		cacheSize = 1
+	}
+	return &TinyLFU{
+		sketch:     sketch.NewCountMin(cacheSize, cacheSize*10),
+		doorkeeper: sketch.NewBloom(cacheSize*10, 4),
+	}
+}
+
+func (t *TinyLFU) Name() string { return "tinylfu" }
+
+func (t *TinyLFU) estimate(address int) int {
+	freq := t.sketch.Estimate(address)
+	if t.doorkeeper.Contains(address) {
+		freq++
+	}
+	return freq
+}
+
+func (t *TinyLFU) Admit(address, victim int, evicting bool) bool {
+	if !evicting {
+		return true
+	}
+	return t.estimate(address) > t.estimate(victim)
+}
+
+func (t *TinyLFU) Record(address int) {
+	// akses pertama hanya masuk doorkeeper
+	if !t.doorkeeper.Add(address) {
+		return
+	}
+	resets := t.sketch.Resets()
+	t.sketch.Increment(address)
+	if t.sketch.Resets() != resets {
+		t.doorkeeper.Reset()
+	}
+}
+
+// Metadata policy ditambahkan ke metadata cache yang dibungkus; riwayat
+// akses policy dihitung sebagai ghost karena datanya tidak ada di cache
+func (nhit *NHit) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(nhit.counts),
+		Ghost:         len(nhit.counts),
+		BytesPerEntry: simulator.MapEntryBytes,
+		FixedBytes:    cap(nhit.ring) * int(unsafe.Sizeof(int(0))),
+	}
+}
+
+func (b *Bloom) Metadata() simulator.Metadata {
+	return simulator.Metadata{FixedBytes: b.filter.Bytes()}
+}
+
+func (t *TinyLFU) Metadata() simulator.Metadata {
+	return simulator.Metadata{FixedBytes: t.sketch.Bytes() + t.doorkeeper.Bytes()}
+}
@@ -0,0 +1,23 @@
+// Package algo mendaftarkan seluruh algoritma ke registry simulator;
+// cukup diimpor dengan blank import oleh CLI.
+package algo
+
+import (
+	_ "ixtza/ajk/wec/algo/clock"
+	_ "ixtza/ajk/wec/algo/clockpro"
+	_ "ixtza/ajk/wec/algo/fifo"
+	_ "ixtza/ajk/wec/algo/larc"
+	_ "ixtza/ajk/wec/algo/lecar"
+	_ "ixtza/ajk/wec/algo/lfu"
+	_ "ixtza/ajk/wec/algo/lirs"
+	_ "ixtza/ajk/wec/algo/lru"
+	_ "ixtza/ajk/wec/algo/lruk"
+	_ "ixtza/ajk/wec/algo/mq"
+	_ "ixtza/ajk/wec/algo/random"
+	_ "ixtza/ajk/wec/algo/s3fifo"
+	_ "ixtza/ajk/wec/algo/sieve"
+	_ "ixtza/ajk/wec/algo/slru"
+	_ "ixtza/ajk/wec/algo/twoq"
+	_ "ixtza/ajk/wec/algo/wec_v5"
+	_ "ixtza/ajk/wec/algo/wtinylfu"
+)
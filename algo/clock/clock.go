@@ -0,0 +1,117 @@
+package clock
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+type (
+	Frame struct {
+		lba int
+		ref bool
+	}
+
+	CLOCK struct {
+		maxlen      int
+		totalaccess int
+		hit         int
+		miss        int
+		pagefault   int
+		write       int
+
+		hand   int
+		frames []Frame
+		index  map[int]int
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "clock",
+		Description: "CLOCK (second chance)",
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewCLOCK(cacheSize)
+		},
+	})
+}
+
+func NewCLOCK(cacheSize int) (*CLOCK, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("clock: cache size must be positive, got %d", cacheSize)
+	}
+	return &CLOCK{
+		maxlen: cacheSize,
+		frames: make([]Frame, 0, cacheSize),
+		index:  make(map[int]int, cacheSize),
+	}, nil
+}
+
+func (clock *CLOCK) Get(trace simulator.Trace) (err error) {
+	clock.totalaccess++
+
+	if idx, ok := clock.index[trace.Addr]; ok {
+		clock.hit++
+		if trace.Op == "W" {
+			clock.write++
+		}
+		clock.frames[idx].ref = true
+		return nil
+	}
+
+	clock.miss++
+	clock.write++
+	if len(clock.frames) < clock.maxlen {
+		clock.index[trace.Addr] = len(clock.frames)
+		clock.frames = append(clock.frames, Frame{lba: trace.Addr})
+		return nil
+	}
+
+	clock.pagefault++
+	// putar jarum sampai ketemu frame dengan bit referensi 0
+	for clock.frames[clock.hand].ref {
+		clock.frames[clock.hand].ref = false
+		clock.hand = (clock.hand + 1) % clock.maxlen
+	}
+	delete(clock.index, clock.frames[clock.hand].lba)
+	clock.frames[clock.hand] = Frame{lba: trace.Addr}
+	clock.index[trace.Addr] = clock.hand
+	clock.hand = (clock.hand + 1) % clock.maxlen
+
+	return nil
+}
+
+func (clock *CLOCK) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("CLOCK\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", clock.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", clock.maxlen))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", clock.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", clock.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", clock.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (clock.hit / clock.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(clock.hit)/float64(clock.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!CLOCK|%d|%d|%d\n", clock.maxlen, clock.hit, clock.write))
+	return err
+}
+
+func (clock *CLOCK) Misses() int {
+	return clock.miss
+}
+
+func (clock *CLOCK) Capacity() int {
+	return clock.maxlen
+}
+
+func (clock *CLOCK) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(clock.index),
+		BytesPerEntry: int(unsafe.Sizeof(Frame{})) + simulator.MapEntryBytes,
+	}
+}
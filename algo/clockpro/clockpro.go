@@ -0,0 +1,311 @@
+package clockpro
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+type (
+	Page struct {
+		lba      int
+		hot      bool
+		resident bool
+		test     bool
+		ref      bool
+
+		next *Page
+		prev *Page
+	}
+
+	// CLOCKPro mengikuti CLOCK-Pro (Jiang, Chen, Zhang 2005): satu clock
+	// berisi halaman hot, cold resident dan cold non-resident yang masih
+	// dalam periode uji, dengan target cold (coldTarget) yang adaptif.
+	CLOCKPro struct {
+		maxlen      int
+		totalaccess int
+		hit         int
+		miss        int
+		pagefault   int
+		write       int
+
+		coldTarget    int
+		countHot      int
+		countCold     int
+		countTest     int
+		coldPromotion int
+
+		handHot  *Page
+		handCold *Page
+		handTest *Page
+		index    map[int]*Page
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "clockpro",
+		Description: "CLOCK-Pro",
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewCLOCKPro(cacheSize)
+		},
+	})
+}
+
+func NewCLOCKPro(cacheSize int) (*CLOCKPro, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("clockpro: cache size must be positive, got %d", cacheSize)
+	}
+	return &CLOCKPro{
+		maxlen:     cacheSize,
+		coldTarget: cacheSize,
+		index:      make(map[int]*Page, cacheSize*2),
+	}, nil
+}
+
+func (cp *CLOCKPro) Get(trace simulator.Trace) (err error) {
+	cp.totalaccess++
+
+	page, ok := cp.index[trace.Addr]
+	if ok && page.resident {
+		cp.hit++
+		if trace.Op == "W" {
+			cp.write++
+		}
+		page.ref = true
+		return nil
+	}
+
+	cp.miss++
+	cp.write++
+	cp.freeFrame()
+
+	// freeFrame bisa saja menghapus test page ini dari clock
+	page, ok = cp.index[trace.Addr]
+	if ok {
+		// diakses lagi dalam periode uji: jarak reuse lebih kecil dari
+		// halaman hot, perbesar target cold dan jadikan hot
+		if cp.coldTarget < cp.maxlen {
+			cp.coldTarget++
+		}
+		cp.countTest--
+		cp.countHot++
+		cp.coldPromotion++
+		page.hot = true
+		page.resident = true
+		page.test = false
+		page.ref = false
+		cp.moveToHead(page)
+		for cp.countHot > cp.maxlen-cp.coldTarget && cp.countHot > 0 {
+			cp.runHandHot()
+		}
+		return nil
+	}
+
+	page = &Page{lba: trace.Addr, resident: true, test: true}
+	cp.index[trace.Addr] = page
+	cp.insertHead(page)
+	cp.countCold++
+
+	return nil
+}
+
+// freeFrame mengeluarkan satu halaman cold resident jika cache penuh
+func (cp *CLOCKPro) freeFrame() {
+	if cp.countHot+cp.countCold < cp.maxlen {
+		return
+	}
+	cp.pagefault++
+	for cp.countCold == 0 {
+		cp.runHandHot()
+	}
+	cp.runHandCold()
+}
+
+func (cp *CLOCKPro) runHandCold() {
+	for {
+		page := cp.handCold
+		if page.hot || !page.resident {
+			cp.handCold = page.next
+			continue
+		}
+		if page.ref {
+			page.ref = false
+			if page.test {
+				page.hot = true
+				page.test = false
+				cp.countCold--
+				cp.countHot++
+				cp.handCold = page.next
+				for cp.countHot > cp.maxlen-cp.coldTarget && cp.countCold == 0 {
+					cp.runHandHot()
+				}
+			} else {
+				page.test = true
+				cp.handCold = page.next
+				cp.moveToHead(page)
+			}
+			continue
+		}
+
+		cp.handCold = page.next
+		page.resident = false
+		cp.countCold--
+		if page.test {
+			cp.countTest++
+			for cp.countTest > cp.maxlen {
+				cp.runHandTest()
+			}
+		} else {
+			cp.remove(page)
+		}
+		return
+	}
+}
+
+// runHandHot menurunkan satu halaman hot menjadi cold
+func (cp *CLOCKPro) runHandHot() {
+	if cp.countHot == 0 {
+		return
+	}
+	for {
+		page := cp.handHot
+		cp.handHot = page.next
+		if !page.hot {
+			// periode uji halaman cold yang dilewati berakhir
+			if page.test {
+				page.test = false
+				if !page.resident {
+					cp.countTest--
+					cp.remove(page)
+					if cp.coldTarget > 1 {
+						cp.coldTarget--
+					}
+				}
+			}
+			continue
+		}
+		if page.ref {
+			page.ref = false
+			continue
+		}
+		page.hot = false
+		cp.countHot--
+		cp.countCold++
+		return
+	}
+}
+
+// runHandTest mengakhiri periode uji sampai satu test page terbuang
+func (cp *CLOCKPro) runHandTest() {
+	if cp.countTest == 0 {
+		return
+	}
+	for {
+		page := cp.handTest
+		cp.handTest = page.next
+		if page.hot || !page.test {
+			continue
+		}
+		page.test = false
+		if page.resident {
+			continue
+		}
+		cp.countTest--
+		cp.remove(page)
+		if cp.coldTarget > 1 {
+			cp.coldTarget--
+		}
+		return
+	}
+}
+
+// insertHead menaruh halaman tepat di belakang jarum hot
+func (cp *CLOCKPro) insertHead(page *Page) {
+	if cp.handHot == nil {
+		page.next = page
+		page.prev = page
+		cp.handHot = page
+		cp.handCold = page
+		cp.handTest = page
+		return
+	}
+	head := cp.handHot
+	page.next = head
+	page.prev = head.prev
+	head.prev.next = page
+	head.prev = page
+}
+
+func (cp *CLOCKPro) unlink(page *Page) {
+	if page.next == page {
+		cp.handHot = nil
+		cp.handCold = nil
+		cp.handTest = nil
+	} else {
+		if cp.handHot == page {
+			cp.handHot = page.next
+		}
+		if cp.handCold == page {
+			cp.handCold = page.next
+		}
+		if cp.handTest == page {
+			cp.handTest = page.next
+		}
+		page.prev.next = page.next
+		page.next.prev = page.prev
+	}
+	page.next = nil
+	page.prev = nil
+}
+
+func (cp *CLOCKPro) remove(page *Page) {
+	cp.unlink(page)
+	delete(cp.index, page.lba)
+}
+
+func (cp *CLOCKPro) moveToHead(page *Page) {
+	cp.unlink(page)
+	cp.insertHead(page)
+}
+
+func (cp *CLOCKPro) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("CLOCK-Pro\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", cp.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", cp.maxlen))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", cp.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", cp.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", cp.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (cp.hit / cp.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(cp.hit)/float64(cp.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("hot pages : %d\n", cp.countHot))
+	file.WriteString(fmt.Sprintf("cold pages : %d\n", cp.countCold))
+	file.WriteString(fmt.Sprintf("test pages : %d\n", cp.countTest))
+	file.WriteString(fmt.Sprintf("cold target : %d\n", cp.coldTarget))
+	file.WriteString(fmt.Sprintf("test promotion : %d\n", cp.coldPromotion))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!CLOCKPRO|%d|%d|%d\n", cp.maxlen, cp.hit, cp.write))
+	return err
+}
+
+func (cp *CLOCKPro) Misses() int {
+	return cp.miss
+}
+
+func (cp *CLOCKPro) Capacity() int {
+	return cp.maxlen
+}
+
+func (cp *CLOCKPro) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(cp.index),
+		Ghost:         cp.countTest,
+		BytesPerEntry: int(unsafe.Sizeof(Page{})) + simulator.MapEntryBytes,
+	}
+}
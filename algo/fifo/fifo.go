@@ -0,0 +1,95 @@
+package fifo
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"ixtza/ajk/wec/algo/internal/queue"
+	"ixtza/ajk/wec/simulator"
+)
+
+type FIFO struct {
+	maxlen      int
+	totalaccess int
+	hit         int
+	miss        int
+	pagefault   int
+	write       int
+
+	queue *queue.Queue[struct{}]
+}
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "fifo",
+		Description: "First In First Out",
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewFIFO(cacheSize)
+		},
+	})
+}
+
+func NewFIFO(cacheSize int) (*FIFO, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("fifo: cache size must be positive, got %d", cacheSize)
+	}
+	return &FIFO{
+		maxlen: cacheSize,
+		queue:  queue.New[struct{}](),
+	}, nil
+}
+
+func (fifo *FIFO) Get(trace simulator.Trace) (err error) {
+	fifo.totalaccess++
+
+	if fifo.queue.Contains(trace.Addr) {
+		fifo.hit++
+		if trace.Op == "W" {
+			fifo.write++
+		}
+		return nil
+	}
+
+	fifo.miss++
+	fifo.write++
+	if fifo.queue.Len() >= fifo.maxlen {
+		fifo.pagefault++
+		fifo.queue.Release(fifo.queue.PopBack())
+	}
+	fifo.queue.PushFront(trace.Addr, struct{}{})
+
+	return nil
+}
+
+func (fifo *FIFO) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("FIFO\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", fifo.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", fifo.maxlen))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", fifo.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", fifo.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", fifo.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (fifo.hit / fifo.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(fifo.hit)/float64(fifo.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!FIFO|%d|%d|%d\n", fifo.maxlen, fifo.hit, fifo.write))
+	return err
+}
+
+func (fifo *FIFO) Misses() int {
+	return fifo.miss
+}
+
+func (fifo *FIFO) Capacity() int {
+	return fifo.maxlen
+}
+
+func (fifo *FIFO) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       fifo.queue.Len(),
+		BytesPerEntry: fifo.queue.EntryBytes(),
+	}
+}
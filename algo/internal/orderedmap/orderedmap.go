@@ -0,0 +1,197 @@
+// Package orderedmap adalah versi bertipe dari github.com/secnot/orderedmap
+// dengan key alamat blok (int), tanpa boxing interface{} dan type assertion.
+// Semantik Set, Delete, Move dan iterator sama persis dengan versi aslinya.
+package orderedmap
+
+import (
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+type node[V any] struct {
+	key   int
+	value V
+	next  *node[V]
+	prev  *node[V]
+	free  *node[V]
+}
+
+// OrderedMap menyimpan urutan penyisipan; elemen pertama adalah yang
+// paling lama
+type OrderedMap[V any] struct {
+	table map[int]*node[V]
+	root  node[V]
+
+	// node yang dihapus dipakai ulang oleh Set bila pooled
+	pooled bool
+	free   *node[V]
+}
+
+func New[V any]() *OrderedMap[V] {
+	om := &OrderedMap[V]{table: map[int]*node[V]{}}
+	om.root.next = &om.root
+	om.root.prev = &om.root
+	return om
+}
+
+// NewPooled seperti New tetapi node yang dihapus dipakai ulang sehingga
+// Set tidak mengalokasi; iterator tidak boleh dilanjutkan setelah Set
+// dipanggil pada map yang elemennya dihapus selama iterasi
+func NewPooled[V any]() *OrderedMap[V] {
+	om := New[V]()
+	om.pooled = true
+	return om
+}
+
+func (om *OrderedMap[V]) Len() int {
+	return len(om.table)
+}
+
+// Set mengganti nilai key yang sudah ada tanpa mengubah posisinya, key
+// baru disisipkan di akhir
+func (om *OrderedMap[V]) Set(key int, value V) {
+	if n, ok := om.table[key]; ok {
+		n.value = value
+		return
+	}
+	n := om.free
+	if n != nil {
+		om.free = n.free
+		n.free = nil
+		n.key, n.value, n.next, n.prev = key, value, &om.root, om.root.prev
+	} else {
+		n = &node[V]{key: key, value: value, next: &om.root, prev: om.root.prev}
+	}
+	om.root.prev.next = n
+	om.root.prev = n
+	om.table[key] = n
+}
+
+func (om *OrderedMap[V]) Get(key int) (value V, ok bool) {
+	n, ok := om.table[key]
+	if !ok {
+		return value, false
+	}
+	return n.value, true
+}
+
+func (om *OrderedMap[V]) Contains(key int) bool {
+	_, ok := om.table[key]
+	return ok
+}
+
+func (om *OrderedMap[V]) GetFirst() (key int, value V, ok bool) {
+	if len(om.table) == 0 {
+		return 0, value, false
+	}
+	n := om.root.next
+	return n.key, n.value, true
+}
+
+func (om *OrderedMap[V]) GetLast() (key int, value V, ok bool) {
+	if len(om.table) == 0 {
+		return 0, value, false
+	}
+	n := om.root.prev
+	return n.key, n.value, true
+}
+
+// Delete melepas key; pointer node yang dihapus dibiarkan sehingga
+// iterator yang sedang berada di node tersebut tetap bisa lanjut
+func (om *OrderedMap[V]) Delete(key int) {
+	n, ok := om.table[key]
+	if !ok {
+		return
+	}
+	n.next.prev = n.prev
+	n.prev.next = n.next
+	delete(om.table, key)
+	if om.pooled {
+		var zero V
+		n.value = zero
+		n.free = om.free
+		om.free = n
+	}
+}
+
+func (om *OrderedMap[V]) PopFirst() (key int, value V, ok bool) {
+	key, value, ok = om.GetFirst()
+	if ok {
+		om.Delete(key)
+	}
+	return
+}
+
+func (om *OrderedMap[V]) PopLast() (key int, value V, ok bool) {
+	key, value, ok = om.GetLast()
+	if ok {
+		om.Delete(key)
+	}
+	return
+}
+
+func (om *OrderedMap[V]) move(key int, last bool) bool {
+	n, ok := om.table[key]
+	if !ok {
+		return false
+	}
+	n.next.prev = n.prev
+	n.prev.next = n.next
+	if last {
+		n.next = &om.root
+		n.prev = om.root.prev
+		om.root.prev.next = n
+		om.root.prev = n
+	} else {
+		n.prev = &om.root
+		n.next = om.root.next
+		om.root.next.prev = n
+		om.root.next = n
+	}
+	return true
+}
+
+func (om *OrderedMap[V]) MoveLast(key int) bool {
+	return om.move(key, true)
+}
+
+func (om *OrderedMap[V]) MoveFirst(key int) bool {
+	return om.move(key, false)
+}
+
+// Iterator berjalan dari elemen paling lama (Iter) atau terbaru (IterReverse)
+type Iterator[V any] struct {
+	curr    *node[V]
+	root    *node[V]
+	reverse bool
+}
+
+func (om *OrderedMap[V]) Iter() Iterator[V] {
+	return Iterator[V]{curr: &om.root, root: &om.root}
+}
+
+func (om *OrderedMap[V]) IterReverse() Iterator[V] {
+	return Iterator[V]{curr: &om.root, root: &om.root, reverse: true}
+}
+
+func (it *Iterator[V]) Next() (key int, value V, ok bool) {
+	if it.curr == nil {
+		return 0, value, false
+	}
+	if it.reverse {
+		it.curr = it.curr.prev
+	} else {
+		it.curr = it.curr.next
+	}
+	if it.curr == it.root {
+		it.curr = nil
+		return 0, value, false
+	}
+	return it.curr.key, it.curr.value, true
+}
+
+// EntryBytes memperkirakan memori satu entri termasuk indeks map
+func (om *OrderedMap[V]) EntryBytes() int {
+	return int(unsafe.Sizeof(node[V]{})) + simulator.MapEntryBytes
+}
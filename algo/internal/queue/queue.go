@@ -0,0 +1,249 @@
+package queue
+
+import (
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Entry adalah elemen Queue, berisi alamat blok dan nilai tambahan
+type Entry[V any] struct {
+	Key   int
+	Value V
+
+	next *Entry[V]
+	prev *Entry[V]
+	list *Queue[V]
+}
+
+// Next mengarah ke belakang (menjauhi Front), nil di ujung antrian
+func (e *Entry[V]) Next() *Entry[V] {
+	if p := e.next; e.list != nil && p != &e.list.root {
+		return p
+	}
+	return nil
+}
+
+// Prev mengarah ke depan (mendekati Front), nil di ujung antrian
+func (e *Entry[V]) Prev() *Entry[V] {
+	if p := e.prev; e.list != nil && p != &e.list.root {
+		return p
+	}
+	return nil
+}
+
+// Queue adalah doubly linked list berindeks alamat blok, sebagai
+// pengganti kombinasi container/list + map/LLRB yang berulang di tiap
+// simulator. Satu alamat hanya bisa muncul sekali.
+type Queue[V any] struct {
+	root  Entry[V]
+	index map[int]*Entry[V]
+
+	// entri yang sudah dilepas lewat Release, dipakai ulang oleh
+	// PushFront/PushBack agar tidak ada alokasi per request
+	free *Entry[V]
+}
+
+func New[V any]() *Queue[V] {
+	q := &Queue[V]{index: map[int]*Entry[V]{}}
+	q.root.next = &q.root
+	q.root.prev = &q.root
+	return q
+}
+
+func (q *Queue[V]) Len() int {
+	return len(q.index)
+}
+
+func (q *Queue[V]) Contains(key int) bool {
+	_, ok := q.index[key]
+	return ok
+}
+
+func (q *Queue[V]) Get(key int) (e *Entry[V], ok bool) {
+	e, ok = q.index[key]
+	return
+}
+
+func (q *Queue[V]) Front() *Entry[V] {
+	if len(q.index) == 0 {
+		return nil
+	}
+	return q.root.next
+}
+
+func (q *Queue[V]) Back() *Entry[V] {
+	if len(q.index) == 0 {
+		return nil
+	}
+	return q.root.prev
+}
+
+func (q *Queue[V]) insert(e, at *Entry[V]) *Entry[V] {
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+	e.list = q
+	return e
+}
+
+func (q *Queue[V]) unlink(e *Entry[V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.next = nil
+	e.prev = nil
+}
+
+// PushFront menambah key di depan; jika key sudah ada nilainya diganti
+// dan posisinya dipindah ke depan
+func (q *Queue[V]) PushFront(key int, value V) *Entry[V] {
+	if e, ok := q.index[key]; ok {
+		e.Value = value
+		q.MoveToFront(e)
+		return e
+	}
+	e := q.insert(q.alloc(key, value), &q.root)
+	q.index[key] = e
+	return e
+}
+
+// PushBack menambah key di belakang; jika key sudah ada nilainya diganti
+// dan posisinya dipindah ke belakang
+func (q *Queue[V]) PushBack(key int, value V) *Entry[V] {
+	if e, ok := q.index[key]; ok {
+		e.Value = value
+		q.MoveToBack(e)
+		return e
+	}
+	e := q.insert(q.alloc(key, value), q.root.prev)
+	q.index[key] = e
+	return e
+}
+
+func (q *Queue[V]) alloc(key int, value V) *Entry[V] {
+	e := q.free
+	if e == nil {
+		return &Entry[V]{Key: key, Value: value}
+	}
+	q.free = e.next
+	e.next = nil
+	e.Key = key
+	e.Value = value
+	return e
+}
+
+// Release mengembalikan entri yang sudah dikeluarkan dari antrian ke pool;
+// entri tidak boleh dipakai lagi oleh pemanggil setelahnya
+func (q *Queue[V]) Release(e *Entry[V]) {
+	if e == nil || e.list != nil {
+		return
+	}
+	var zero V
+	e.Value = zero
+	e.next = q.free
+	q.free = e
+}
+
+func (q *Queue[V]) MoveToFront(e *Entry[V]) {
+	if e.list != q || q.root.next == e {
+		return
+	}
+	q.unlink(e)
+	q.insert(e, &q.root)
+}
+
+func (q *Queue[V]) MoveToBack(e *Entry[V]) {
+	if e.list != q || q.root.prev == e {
+		return
+	}
+	q.unlink(e)
+	q.insert(e, q.root.prev)
+}
+
+func (q *Queue[V]) Remove(e *Entry[V]) V {
+	if e.list == q {
+		q.unlink(e)
+		e.list = nil
+		delete(q.index, e.Key)
+	}
+	return e.Value
+}
+
+// Delete mengeluarkan key dan mengembalikan entrinya ke pool karena
+// pemanggil hanya menerima nilainya
+func (q *Queue[V]) Delete(key int) (value V, ok bool) {
+	e, ok := q.index[key]
+	if !ok {
+		return value, false
+	}
+	value = q.Remove(e)
+	q.Release(e)
+	return value, true
+}
+
+func (q *Queue[V]) PopFront() (e *Entry[V]) {
+	e = q.Front()
+	if e != nil {
+		q.Remove(e)
+	}
+	return e
+}
+
+func (q *Queue[V]) PopBack() (e *Entry[V]) {
+	e = q.Back()
+	if e != nil {
+		q.Remove(e)
+	}
+	return e
+}
+
+// Ghost adalah FIFO alamat blok tanpa data dengan kapasitas terbatas,
+// dipakai untuk mencatat blok yang baru saja keluar dari cache
+type Ghost struct {
+	capacity int
+	queue    *Queue[struct{}]
+}
+
+func NewGhost(capacity int) *Ghost {
+	return &Ghost{
+		capacity: capacity,
+		queue:    New[struct{}](),
+	}
+}
+
+// Add mencatat key sebagai yang terbaru dan membuang yang paling lama
+// jika kapasitas terlampaui
+func (g *Ghost) Add(key int) {
+	g.queue.PushFront(key, struct{}{})
+	for g.queue.Len() > g.capacity {
+		g.queue.Release(g.queue.PopBack())
+	}
+}
+
+func (g *Ghost) Contains(key int) bool {
+	return g.queue.Contains(key)
+}
+
+func (g *Ghost) Remove(key int) bool {
+	e, ok := g.queue.Get(key)
+	if !ok {
+		return false
+	}
+	g.queue.Remove(e)
+	g.queue.Release(e)
+	return true
+}
+
+func (g *Ghost) Len() int {
+	return g.queue.Len()
+}
+
+func (g *Ghost) Capacity() int {
+	return g.capacity
+}
+
+// EntryBytes memperkirakan memori satu entri termasuk indeks map
+func (q *Queue[V]) EntryBytes() int {
+	return int(unsafe.Sizeof(Entry[V]{})) + simulator.MapEntryBytes
+}
@@ -0,0 +1,30 @@
+package queue
+
+import "testing"
+
+// TestReleaseReuses memastikan entri yang dilepas lewat PopBack+Release,
+// Delete dan Ghost dipakai ulang sehingga antrean penuh tidak lagi
+// mengalokasikan entri
+func TestReleaseReuses(t *testing.T) {
+	q := New[int]()
+	ghost := NewGhost(64)
+	for key := 0; key < 64; key++ {
+		q.PushFront(key, key)
+		ghost.Add(key)
+	}
+	key := 64
+	allocs := testing.AllocsPerRun(1000, func() {
+		q.Release(q.PopBack())
+		q.PushFront(key, key)
+		q.Delete(key)
+		q.PushBack(key, key)
+		ghost.Add(key)
+		key++
+	})
+	if allocs > 0 {
+		t.Fatalf("%v allocations per run", allocs)
+	}
+	if q.Len() != 64 || ghost.Len() != 64 {
+		t.Fatalf("queue %d, ghost %d entries", q.Len(), ghost.Len())
+	}
+}
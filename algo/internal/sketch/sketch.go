@@ -0,0 +1,164 @@
+package sketch
+
+// hash mencampur alamat blok dengan seed per baris (splitmix64)
+func hash(key int, seed uint64) uint64 {
+	x := uint64(key) + seed*0x9e3779b97f4a7c15
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+const (
+	depth      = 4
+	maxCounter = 15
+)
+
+// CountMin adalah count-min sketch dengan counter 4 bit dan penuaan berkala:
+// setelah sampleSize penambahan seluruh counter dibagi dua.
+type CountMin struct {
+	width      int
+	mask       uint64
+	rows       [depth][]uint8
+	additions  int
+	sampleSize int
+	resets     int
+}
+
+func NewCountMin(width, sampleSize int) *CountMin {
+	if width < 16 {
+		width = 16
+	}
+	width = nextPowerOfTwo(width)
+	cm := &CountMin{
+		width:      width,
+		mask:       uint64(width - 1),
+		sampleSize: sampleSize,
+	}
+	for i := 0; i < depth; i++ {
+		cm.rows[i] = make([]uint8, width)
+	}
+	return cm
+}
+
+func (cm *CountMin) Increment(key int) {
+	added := false
+	for i := 0; i < depth; i++ {
+		idx := hash(key, uint64(i+1)) & cm.mask
+		if cm.rows[i][idx] < maxCounter {
+			cm.rows[i][idx]++
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+	cm.additions++
+	if cm.sampleSize > 0 && cm.additions >= cm.sampleSize {
+		cm.Halve()
+	}
+}
+
+func (cm *CountMin) Estimate(key int) int {
+	min := maxCounter
+	for i := 0; i < depth; i++ {
+		idx := hash(key, uint64(i+1)) & cm.mask
+		if int(cm.rows[i][idx]) < min {
+			min = int(cm.rows[i][idx])
+		}
+	}
+	return min
+}
+
+// Halve membagi dua seluruh counter (reset pada TinyLFU)
+func (cm *CountMin) Halve() {
+	for i := 0; i < depth; i++ {
+		for j := range cm.rows[i] {
+			cm.rows[i][j] >>= 1
+		}
+	}
+	cm.additions /= 2
+	cm.resets++
+}
+
+func (cm *CountMin) Resets() int {
+	return cm.resets
+}
+
+// Bloom adalah bloom filter sederhana yang dapat dikosongkan ulang.
+type Bloom struct {
+	bits   []uint64
+	mask   uint64
+	hashes int
+	count  int
+}
+
+func NewBloom(bits, hashes int) *Bloom {
+	if bits < 64 {
+		bits = 64
+	}
+	bits = nextPowerOfTwo(bits)
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &Bloom{
+		bits:   make([]uint64, bits/64),
+		mask:   uint64(bits - 1),
+		hashes: hashes,
+	}
+}
+
+func (b *Bloom) Contains(key int) bool {
+	for i := 0; i < b.hashes; i++ {
+		idx := hash(key, uint64(i+101)) & b.mask
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add memasukkan key dan mengembalikan true jika key sudah ada sebelumnya
+func (b *Bloom) Add(key int) (exists bool) {
+	exists = true
+	for i := 0; i < b.hashes; i++ {
+		idx := hash(key, uint64(i+101)) & b.mask
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			exists = false
+			b.bits[idx/64] |= 1 << (idx % 64)
+		}
+	}
+	if !exists {
+		b.count++
+	}
+	return exists
+}
+
+func (b *Bloom) Len() int {
+	return b.count
+}
+
+func (b *Bloom) Reset() {
+	for i := range b.bits {
+		b.bits[i] = 0
+	}
+	b.count = 0
+}
+
+func (cm *CountMin) Bytes() int {
+	return depth * cm.width
+}
+
+func (b *Bloom) Bytes() int {
+	return len(b.bits) * 8
+}
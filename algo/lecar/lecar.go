@@ -0,0 +1,316 @@
+package lecar
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+	"unsafe"
+
+	"github.com/tidwall/btree"
+	"ixtza/ajk/wec/algo/internal/queue"
+	"ixtza/ajk/wec/simulator"
+)
+
+const (
+	expertLRU = iota
+	expertLFU
+)
+
+type (
+	Node struct {
+		lba  int
+		freq int
+		last int
+	}
+
+	History struct {
+		evictTime int
+		freq      int
+	}
+
+	// LeCaR mengikuti LeCaR (Vietri dkk. 2018): dua expert LRU dan LFU
+	// dengan bobot yang diperbarui dari regret, yaitu miss pada blok yang
+	// masih tercatat di history expert yang membuangnya. Jika adaptive
+	// aktif, learning rate ikut diubah tiap window seperti pada CACHEUS
+	// (Rodriguez dkk. 2021).
+	LeCaR struct {
+		name        string
+		maxlen      int
+		totalaccess int
+		hit         int
+		miss        int
+		pagefault   int
+		write       int
+		regretLRU   int
+		regretLFU   int
+		evictLRU    int
+		evictLFU    int
+
+		weightLRU    float64
+		weightLFU    float64
+		learningRate float64
+		discount     float64
+
+		adaptive       bool
+		windowHits     int
+		prevHitRate    float64
+		prevRate       float64
+		unlearnCount   int
+		learningUpdate int
+
+		rand       *rand.Rand
+		recency    *queue.Queue[*Node]
+		frequency  *btree.BTreeG[*Node]
+		historyLRU *queue.Queue[History]
+		historyLFU *queue.Queue[History]
+	}
+)
+
+func lessNode(a, b *Node) bool {
+	if a.freq != b.freq {
+		return a.freq < b.freq
+	}
+	if a.last != b.last {
+		return a.last < b.last
+	}
+	return a.lba < b.lba
+}
+
+func newLeCaR(name string, cacheSize int, learningRate float64, seed int64, adaptive bool) *LeCaR {
+	return &LeCaR{
+		name:         name,
+		maxlen:       cacheSize,
+		weightLRU:    0.5,
+		weightLFU:    0.5,
+		learningRate: learningRate,
+		discount:     math.Pow(0.005, 1/float64(cacheSize)),
+		adaptive:     adaptive,
+		rand:         rand.New(rand.NewSource(seed)),
+		recency:      queue.New[*Node](),
+		frequency:    btree.NewBTreeG[*Node](lessNode),
+		historyLRU:   queue.New[History](),
+		historyLFU:   queue.New[History](),
+	}
+}
+
+func init() {
+	// LeCaR dan CACHEUS berbagi flag parameter
+	shared := []simulator.Param{
+		{Name: "lecar-learning-rate", Kind: simulator.ParamFloat, Default: 0.45, Usage: "learning rate awal LeCaR/CACHEUS"},
+		{Name: "lecar-seed", Kind: simulator.ParamInt64, Default: int64(1), Usage: "seed random pemilihan expert LeCaR/CACHEUS"},
+	}
+	simulator.Register(simulator.Algorithm{
+		Name:        "lecar",
+		Description: "LeCaR (regret minimization LRU/LFU)",
+		Params:      shared,
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewLeCaR(cacheSize, params.Float("lecar-learning-rate"), params.Int64("lecar-seed"))
+		},
+	})
+	simulator.Register(simulator.Algorithm{
+		Name:        "cacheus",
+		Description: "CACHEUS (LeCaR dengan learning rate adaptif)",
+		Params:      shared,
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewCACHEUS(cacheSize, params.Float("lecar-learning-rate"), params.Int64("lecar-seed"))
+		},
+	})
+}
+
+func NewLeCaR(cacheSize int, learningRate float64, seed int64) (*LeCaR, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("lecar: cache size must be positive, got %d", cacheSize)
+	}
+	if learningRate <= 0 {
+		return nil, fmt.Errorf("lecar: learning rate must be positive, got %v", learningRate)
+	}
+	return newLeCaR("LeCaR", cacheSize, learningRate, seed, false), nil
+}
+
+// NewCACHEUS memakai learning rate adaptif yang dimulai dari learningRate
+func NewCACHEUS(cacheSize int, learningRate float64, seed int64) (*LeCaR, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("cacheus: cache size must be positive, got %d", cacheSize)
+	}
+	if learningRate <= 0 {
+		return nil, fmt.Errorf("cacheus: learning rate must be positive, got %v", learningRate)
+	}
+	return newLeCaR("CACHEUS", cacheSize, learningRate, seed, true), nil
+}
+
+func (lecar *LeCaR) addHistory(history *queue.Queue[History], node *Node) {
+	history.PushFront(node.lba, History{evictTime: lecar.totalaccess, freq: node.freq})
+	if history.Len() > lecar.maxlen {
+		history.Release(history.PopBack())
+	}
+}
+
+func (lecar *LeCaR) evict() {
+	lecar.pagefault++
+
+	victimLRU := lecar.recency.Back().Value
+	victimLFU, _ := lecar.frequency.Min()
+
+	victim := victimLRU
+	expert := expertLRU
+	if victimLRU != victimLFU && lecar.rand.Float64() >= lecar.weightLRU {
+		victim = victimLFU
+		expert = expertLFU
+	}
+
+	lecar.recency.Delete(victim.lba)
+	lecar.frequency.Delete(victim)
+	if expert == expertLRU {
+		lecar.evictLRU++
+		lecar.addHistory(lecar.historyLRU, victim)
+	} else {
+		lecar.evictLFU++
+		lecar.addHistory(lecar.historyLFU, victim)
+	}
+}
+
+// regret menaikkan bobot expert lain jika blok yang miss dibuang oleh
+// expert ini, semakin baru dibuang semakin besar hukumannya
+func (lecar *LeCaR) regret(history History, expert int) {
+	reward := math.Pow(lecar.discount, float64(lecar.totalaccess-history.evictTime))
+	if expert == expertLRU {
+		lecar.regretLRU++
+		lecar.weightLFU *= math.Exp(lecar.learningRate * reward)
+	} else {
+		lecar.regretLFU++
+		lecar.weightLRU *= math.Exp(lecar.learningRate * reward)
+	}
+	total := lecar.weightLRU + lecar.weightLFU
+	lecar.weightLRU /= total
+	lecar.weightLFU /= total
+}
+
+func (lecar *LeCaR) updateLearningRate() {
+	window := lecar.maxlen
+	if !lecar.adaptive || lecar.totalaccess%window != 0 {
+		return
+	}
+	hitRate := float64(lecar.windowHits) / float64(window)
+	deltaHitRate := hitRate - lecar.prevHitRate
+	deltaRate := lecar.learningRate - lecar.prevRate
+	lecar.prevHitRate = hitRate
+	lecar.prevRate = lecar.learningRate
+	lecar.windowHits = 0
+
+	if math.Abs(deltaRate) > 1e-9 {
+		// lanjutkan arah perubahan jika hit rate membaik, balik jika memburuk
+		sign := -1.0
+		if (deltaHitRate > 0 && deltaRate > 0) || (deltaHitRate < 0 && deltaRate < 0) {
+			sign = 1.0
+		}
+		lecar.learningRate += sign * math.Abs(lecar.learningRate*deltaRate)
+		lecar.learningRate = math.Min(1, math.Max(0.001, lecar.learningRate))
+		lecar.unlearnCount = 0
+		lecar.learningUpdate++
+		return
+	}
+
+	if hitRate == 0 || deltaHitRate <= 0 {
+		lecar.unlearnCount++
+	}
+	if lecar.unlearnCount >= 10 {
+		lecar.unlearnCount = 0
+		lecar.learningRate = 0.001 + lecar.rand.Float64()*(1-0.001)
+		lecar.learningUpdate++
+	}
+}
+
+func (lecar *LeCaR) Get(trace simulator.Trace) (err error) {
+	lecar.totalaccess++
+	defer lecar.updateLearningRate()
+
+	if e, ok := lecar.recency.Get(trace.Addr); ok {
+		lecar.hit++
+		lecar.windowHits++
+		if trace.Op == "W" {
+			lecar.write++
+		}
+		node := e.Value
+		lecar.frequency.Delete(node)
+		node.freq++
+		node.last = lecar.totalaccess
+		lecar.frequency.Set(node)
+		lecar.recency.MoveToFront(e)
+		return nil
+	}
+
+	lecar.miss++
+	lecar.write++
+
+	node := &Node{lba: trace.Addr, freq: 1, last: lecar.totalaccess}
+	if history, ok := lecar.historyLRU.Delete(trace.Addr); ok {
+		lecar.regret(history, expertLRU)
+		node.freq = history.freq + 1
+	} else if history, ok := lecar.historyLFU.Delete(trace.Addr); ok {
+		lecar.regret(history, expertLFU)
+		node.freq = history.freq + 1
+	}
+
+	if lecar.recency.Len() >= lecar.maxlen {
+		lecar.evict()
+	}
+	lecar.recency.PushFront(node.lba, node)
+	lecar.frequency.Set(node)
+
+	return nil
+}
+
+func (lecar *LeCaR) Sample() []simulator.Metric {
+	return []simulator.Metric{
+		{Name: "hit_ratio", Value: float64(lecar.hit) / float64(lecar.totalaccess)},
+		{Name: "w_lru", Value: lecar.weightLRU},
+		{Name: "w_lfu", Value: lecar.weightLFU},
+		{Name: "learning_rate", Value: lecar.learningRate},
+	}
+}
+
+func (lecar *LeCaR) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString(fmt.Sprintf("%s\n", lecar.name))
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", lecar.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", lecar.maxlen))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", lecar.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", lecar.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", lecar.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (lecar.hit / lecar.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(lecar.hit)/float64(lecar.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("lru evictions : %d\n", lecar.evictLRU))
+	file.WriteString(fmt.Sprintf("lfu evictions : %d\n", lecar.evictLFU))
+	file.WriteString(fmt.Sprintf("lru regret : %d\n", lecar.regretLRU))
+	file.WriteString(fmt.Sprintf("lfu regret : %d\n", lecar.regretLFU))
+	file.WriteString(fmt.Sprintf("w_lru : %8.4f\n", lecar.weightLRU))
+	file.WriteString(fmt.Sprintf("w_lfu : %8.4f\n", lecar.weightLFU))
+	file.WriteString(fmt.Sprintf("learning rate : %8.4f\n", lecar.learningRate))
+	if lecar.adaptive {
+		file.WriteString(fmt.Sprintf("learning rate updates : %d\n", lecar.learningUpdate))
+	}
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!%s|%d|%d|%d\n", lecar.name, lecar.maxlen, lecar.hit, lecar.write))
+	return err
+}
+
+func (lecar *LeCaR) Misses() int {
+	return lecar.miss
+}
+
+func (lecar *LeCaR) Capacity() int {
+	return lecar.maxlen
+}
+
+func (lecar *LeCaR) Metadata() simulator.Metadata {
+	ghost := lecar.historyLRU.Len() + lecar.historyLFU.Len()
+	return simulator.Metadata{
+		Tracked:       lecar.recency.Len() + ghost,
+		Ghost:         ghost,
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + lecar.recency.EntryBytes() + int(unsafe.Sizeof(&Node{})),
+	}
+}
@@ -1,25 +1,51 @@
 package lfu
 
 import (
-	"container/list"
+	"encoding/gob"
 	"fmt"
+	"math/bits"
 	"os"
 	"time"
+	"unsafe"
 
 	"ixtza/ajk/wec/simulator"
-	// "github.com/esaiy/golang-lirs/simulator"
-	"github.com/petar/GoLLRB/llrb"
 )
 
+// MAXFREQ adalah batas frekuensi pada mode AgingNone, blok yang sudah
+// mencapainya tidak lagi dipindah bucket
 const MAXFREQ = 1000
 
+const (
+	AgingNone      = "none"
+	AgingHalving   = "halving"
+	AgingDynamic   = "dynamic"
+	AgingUnbounded = "unbounded"
+)
+
 type (
-	Node = struct {
-		lba  int
-		freq int
-		op   string
-		elem *list.Element
+	// Node adalah blok resident, tersambung secara intrusif ke bucket
+	// prioritasnya; yang terbaru di depan, kandidat eviction di belakang
+	Node struct {
+		lba      int
+		freq     int
+		priority int
+
+		prev   *Node
+		next   *Node
+		bucket *freqBucket
+	}
+
+	// freqBucket berisi semua blok dengan prioritas yang sama dan tersambung
+	// terurut naik, sehingga bucket terkecil selalu root.next
+	freqBucket struct {
+		priority int
+		len      int
+		root     Node
+
+		prev *freqBucket
+		next *freqBucket
 	}
+
 	LFU struct {
 		maxlen      int
 		available   int
@@ -29,11 +55,65 @@ type (
 		pagefault   int
 		write       int
 
-		tlba    *llrb.LLRB
-		freqArr [MAXFREQ]*list.List
+		tlba        map[int]*Node
+		buckets     *freqBucket
+		bucketCount int
+		// bucket kosong yang dipakai ulang
+		freeBuckets *freqBucket
+
+		// selain AgingNone, frekuensi tidak dibatasi MAXFREQ
+		aging       string
+		agingPeriod int
+		inflation   int
+		halvings    int
+
+		// jumlah eviction per bucket frekuensi [2^i, 2^(i+1))
+		evictBucket []int
+
+		simulator.Emitter
 	}
 )
 
+// lfuState adalah isi snapshot LFU; Buckets terurut naik dan blok di
+// dalamnya dari yang terbaru ke kandidat eviction
+type lfuState struct {
+	Available   int
+	TotalAccess int
+	Hit         int
+	Miss        int
+	PageFault   int
+	Write       int
+	Inflation   int
+	Halvings    int
+	EvictBucket []int
+	Buckets     []lfuBucketState
+}
+
+type lfuBucketState struct {
+	Priority int
+	Blocks   []lfuBlockState
+}
+
+type lfuBlockState struct {
+	LBA  int
+	Freq int
+}
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "lfu",
+		Description: "Least Frequently Used",
+		Admission:   true,
+		Params: []simulator.Param{
+			{Name: "lfu-aging", Kind: simulator.ParamString, Default: AgingNone, Usage: "mode penuaan frekuensi LFU\n(none|halving|dynamic|unbounded)"},
+			{Name: "lfu-aging-period", Kind: simulator.ParamInt, Default: 0, Usage: "periode (request) pembagian dua frekuensi untuk lfu-aging halving"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewLFUWithAging(cacheSize, params.String("lfu-aging"), params.Int("lfu-aging-period"))
+		},
+	})
+}
+
 func NewLFU(cacheSize int) *LFU {
 	lfu := &LFU{
 		maxlen:      cacheSize,
@@ -43,91 +123,232 @@ func NewLFU(cacheSize int) *LFU {
 		miss:        0,
 		pagefault:   0,
 		write:       0,
-		tlba:        llrb.New(),
-		freqArr:     [MAXFREQ]*list.List{},
-	}
-	for i := 0; i < MAXFREQ; i++ {
-		lfu.freqArr[i] = list.New()
+		tlba:        make(map[int]*Node, cacheSize),
 	}
+	lfu.buckets = &freqBucket{}
+	lfu.buckets.next = lfu.buckets
+	lfu.buckets.prev = lfu.buckets
+	lfu.aging = AgingNone
 	return lfu
 }
 
-type NodeLba Node
-
-func (x *NodeLba) Less(than llrb.Item) bool {
-	return x.lba < than.(*NodeLba).lba
+// NewLFUWithAging membuat LFU dengan mode penuaan frekuensi:
+// AgingHalving membagi dua semua frekuensi setiap agingPeriod request,
+// AgingDynamic memakai LFU-DA (prioritas = frekuensi + faktor inflasi),
+// AgingUnbounded menghapus batas MAXFREQ tanpa penuaan.
+func NewLFUWithAging(cacheSize int, aging string, agingPeriod int) (*LFU, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("lfu: cache size must be positive, got %d", cacheSize)
+	}
+	switch aging {
+	case "", AgingNone, AgingDynamic, AgingUnbounded:
+	case AgingHalving:
+		if agingPeriod <= 0 {
+			return nil, fmt.Errorf("lfu: aging period must be positive for halving, got %d", agingPeriod)
+		}
+	default:
+		return nil, fmt.Errorf("lfu: unknown aging mode %q (none|halving|dynamic|unbounded)", aging)
+	}
+	lfu := NewLFU(cacheSize)
+	if aging == "" || aging == AgingNone {
+		return lfu, nil
+	}
+	lfu.aging = aging
+	lfu.agingPeriod = agingPeriod
+	return lfu, nil
 }
 
-func (lfu *LFU) put(data *NodeLba) (exists bool) {
-	var el *list.Element
-	kk := new(NodeLba)
-
-	node := lfu.tlba.Get((*NodeLba)(data)) // coba ambil, apa ada ?
-	if node != nil {
+func (lfu *LFU) put(lba int, op string) (exists bool) {
+	node, ok := lfu.tlba[lba]
+	if ok {
 		lfu.hit++
-		dd := node.(*NodeLba) // shortcut saja
-		if data.op == "W" {
+		lfu.Emit(simulator.EventHit, lba, simulator.TierSSD, lfu.totalaccess)
+		if op == "W" {
 			lfu.write++
+			lfu.Emit(simulator.EventSSDWrite, lba, simulator.TierSSD, lfu.totalaccess)
 		}
-		if dd.freq < MAXFREQ { // wes mentok ?
-			lst := lfu.freqArr[dd.freq-1]
-			lst.Remove(dd.elem) // mau dipindah
-			dd.freq++
-			lst = lfu.freqArr[dd.freq-1]
-			el = lst.PushFront(dd.elem.Value) // sip iki
-			dd.elem = el                      // update elem
+		if lfu.aging == AgingNone && node.freq >= MAXFREQ { // wes mentok ?
+			return true
 		}
+		from := node.bucket
+		node.freq++
+		lfu.link(node, lfu.priority(node), from)
+		lfu.unlinkBucket(from, node)
 		return true
-	} else { // not exist
-		lfu.miss++
-		lfu.write++
-		if lfu.available > 0 {
-			lfu.available--
-			el := lfu.freqArr[0].PushFront(data) // selalu 1 khan ?
-			data.elem = el
-			lfu.tlba.InsertNoReplace(data)
-		} else {
-			lfu.pagefault++
-			el = nil
-			for ii := 0; ii < MAXFREQ; ii++ { // cari list yang tidak kosong, terus buang
-				if lfu.freqArr[ii].Len() > 0 {
-					el = lfu.freqArr[ii].Back() // ambil yang paling lama
-					lba := el.Value.(*NodeLba).lba
-					kk.lba = lba
-					lfu.tlba.Delete(kk) // hapus dah
-					lfu.freqArr[ii].Remove(el)
-					break
-				}
-			}
-			//fmt.Printf("len %d:%d    vs", lfu.available, lfu.tlba.Len())
-
-			//if el == nil {
-			//println("ndak mungkinnn")
-			//}
-			el = lfu.freqArr[0].PushFront(data)
-			data.elem = el
-			lfu.tlba.InsertNoReplace(data)
-			//fmt.Printf("     %d:%d\n", cache.totalaccess, cache.tlba.Len())
+	}
+
+	lfu.miss++
+	lfu.Emit(simulator.EventMiss, lba, simulator.TierSSD, lfu.totalaccess)
+	lfu.write++
+	if lfu.available > 0 {
+		lfu.available--
+		node = &Node{}
+	} else {
+		lfu.pagefault++
+		// blok korban dipakai ulang untuk blok baru
+		node = lfu.evict()
+		if node == nil {
+			node = &Node{}
 		}
-		return false
 	}
+	node.lba = lba
+	node.freq = 1
+	lfu.link(node, lfu.priority(node), lfu.buckets)
+	lfu.tlba[lba] = node
+	lfu.Emit(simulator.EventInsert, lba, simulator.TierSSD, lfu.totalaccess)
+	lfu.Emit(simulator.EventSSDWrite, lba, simulator.TierSSD, lfu.totalaccess)
+	return false
+}
+
+// evict membuang blok paling lama pada bucket prioritas terkecil
+func (lfu *LFU) evict() *Node {
+	bucket := lfu.buckets.next
+	if bucket == lfu.buckets {
+		return nil
+	}
+	victim := bucket.root.prev
+	lfu.countEviction(victim.freq)
+	if lfu.aging == AgingDynamic {
+		lfu.inflation = bucket.priority
+	}
+	lfu.unlinkBucket(bucket, victim)
+	delete(lfu.tlba, victim.lba)
+	lfu.Emit(simulator.EventEvict, victim.lba, simulator.TierSSD, lfu.totalaccess)
+	return victim
+}
+
+func (lfu *LFU) priority(node *Node) int {
+	if lfu.aging == AgingDynamic {
+		return node.freq + lfu.inflation
+	}
+	return node.freq
+}
+
+// link memasang node di depan bucket prioritas, mencari posisi bucket
+// mulai dari after; prioritas hanya naik sehingga pada mode tanpa
+// inflasi bucket tujuan selalu tepat setelah bucket asal
+func (lfu *LFU) link(node *Node, priority int, after *freqBucket) {
+	at := after
+	for at.next != lfu.buckets && at.next.priority <= priority {
+		at = at.next
+	}
+	bucket := at
+	if at == lfu.buckets || at.priority != priority {
+		bucket = lfu.newBucket(priority)
+		bucket.prev = at
+		bucket.next = at.next
+		at.next.prev = bucket
+		at.next = bucket
+	}
+	if node.bucket == bucket {
+		return
+	}
+	if node.bucket != nil {
+		node.prev.next = node.next
+		node.next.prev = node.prev
+		node.bucket.len--
+	}
+	node.priority = priority
+	node.bucket = bucket
+	node.prev = &bucket.root
+	node.next = bucket.root.next
+	bucket.root.next.prev = node
+	bucket.root.next = node
+	bucket.len++
+}
+
+// unlinkBucket melepas node dari bucket (jika masih di sana) dan membuang
+// bucket yang menjadi kosong
+func (lfu *LFU) unlinkBucket(bucket *freqBucket, node *Node) {
+	if node.bucket == bucket {
+		node.prev.next = node.next
+		node.next.prev = node.prev
+		node.prev = nil
+		node.next = nil
+		node.bucket = nil
+		bucket.len--
+	}
+	if bucket.len > 0 || bucket == lfu.buckets {
+		return
+	}
+	bucket.prev.next = bucket.next
+	bucket.next.prev = bucket.prev
+	lfu.releaseBucket(bucket)
+}
+
+func (lfu *LFU) releaseBucket(bucket *freqBucket) {
+	bucket.prev = nil
+	bucket.next = lfu.freeBuckets
+	lfu.freeBuckets = bucket
+	lfu.bucketCount--
+}
+
+func (lfu *LFU) newBucket(priority int) *freqBucket {
+	bucket := lfu.freeBuckets
+	if bucket == nil {
+		bucket = &freqBucket{}
+	} else {
+		lfu.freeBuckets = bucket.next
+	}
+	lfu.bucketCount++
+	bucket.len = 0
+	bucket.priority = priority
+	bucket.root.next = &bucket.root
+	bucket.root.prev = &bucket.root
+	return bucket
+}
+
+// halve membagi dua frekuensi semua blok, urutan LRU di dalam satu
+// bucket dipertahankan dengan memasukkan ulang dari yang paling lama
+func (lfu *LFU) halve() {
+	var nodes []*Node
+	for bucket := lfu.buckets.next; bucket != lfu.buckets; bucket = bucket.next {
+		for node := bucket.root.prev; node != &bucket.root; node = node.prev {
+			nodes = append(nodes, node)
+		}
+	}
+	for bucket := lfu.buckets.next; bucket != lfu.buckets; {
+		next := bucket.next
+		lfu.releaseBucket(bucket)
+		bucket = next
+	}
+	lfu.buckets.next = lfu.buckets
+	lfu.buckets.prev = lfu.buckets
+	for _, node := range nodes {
+		node.bucket = nil
+		node.freq /= 2
+		if node.freq < 1 {
+			node.freq = 1
+		}
+		// frekuensi baru tidak turun sepanjang urutan, cukup cari dari
+		// bucket terakhir
+		lfu.link(node, lfu.priority(node), lfu.buckets.prev)
+	}
+	lfu.halvings++
+}
+
+func (lfu *LFU) countEviction(freq int) {
+	bucket := bits.Len(uint(freq)) - 1
+	for len(lfu.evictBucket) <= bucket {
+		lfu.evictBucket = append(lfu.evictBucket, 0)
+	}
+	lfu.evictBucket[bucket]++
 }
 
 func (lfu *LFU) Get(trace simulator.Trace) (err error) {
 	lfu.totalaccess++
-	obj := new(NodeLba)
-	obj.lba = trace.Addr
-	obj.op = trace.Op
-	obj.freq = 1
+	lfu.put(trace.Addr, trace.Op)
 
-	lfu.put(obj)
+	if lfu.aging == AgingHalving && lfu.agingPeriod > 0 && lfu.totalaccess%lfu.agingPeriod == 0 {
+		lfu.halve()
+	}
 
 	return nil
 }
 func (lfu LFU) PrintToFile(file *os.File, timeStart time.Time) (err error) {
 	sum := 0
-	for ii := 0; ii < MAXFREQ; ii++ {
-		sum = sum + lfu.freqArr[ii].Len()
+	for bucket := lfu.buckets.next; bucket != lfu.buckets; bucket = bucket.next {
+		sum = sum + bucket.len
 	}
 	file.WriteString("------------------------------------\n")
 	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", lfu.totalaccess))
@@ -137,10 +358,122 @@ func (lfu LFU) PrintToFile(file *os.File, timeStart time.Time) (err error) {
 	file.WriteString(fmt.Sprintf("ssd write: %d\n", lfu.write))
 	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (lfu.hit / lfu.write)))
 	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(lfu.hit)/float64(lfu.totalaccess))*100))
-	file.WriteString(fmt.Sprintf("isi tree %d\n", lfu.tlba.Len()))
+	file.WriteString(fmt.Sprintf("isi tree %d\n", len(lfu.tlba)))
 	file.WriteString(fmt.Sprintf("isi array: %d\n", sum))
+	file.WriteString(fmt.Sprintf("aging : %s\n", lfu.aging))
+	if lfu.aging == AgingHalving {
+		file.WriteString(fmt.Sprintf("halvings : %d\n", lfu.halvings))
+	}
+	if lfu.aging == AgingDynamic {
+		file.WriteString(fmt.Sprintf("inflation : %d\n", lfu.inflation))
+	}
+	for bucket, count := range lfu.evictBucket {
+		file.WriteString(fmt.Sprintf("evictions freq %d-%d : %d\n", 1<<bucket, (1<<(bucket+1))-1, count))
+	}
 
 	file.WriteString(fmt.Sprintf("!LFU|%d|%d|%d\n", lfu.maxlen, lfu.hit, lfu.write))
 
 	return nil
 }
+
+func (lfu *LFU) Contains(address int) bool {
+	_, ok := lfu.tlba[address]
+	return ok
+}
+
+func (lfu *LFU) Victim() (address int, ok bool) {
+	if lfu.available > 0 {
+		return 0, false
+	}
+	bucket := lfu.buckets.next
+	if bucket == lfu.buckets {
+		return 0, false
+	}
+	return bucket.root.prev.lba, true
+}
+
+func (lfu *LFU) Misses() int {
+	return lfu.miss
+}
+
+func (lfu *LFU) Capacity() int {
+	return lfu.maxlen
+}
+
+func (lfu *LFU) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(lfu.tlba),
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + simulator.MapEntryBytes,
+		FixedBytes:    lfu.bucketCount * int(unsafe.Sizeof(freqBucket{})),
+	}
+}
+
+func (lfu *LFU) Snapshot(enc *gob.Encoder) error {
+	state := lfuState{
+		Available:   lfu.available,
+		TotalAccess: lfu.totalaccess,
+		Hit:         lfu.hit,
+		Miss:        lfu.miss,
+		PageFault:   lfu.pagefault,
+		Write:       lfu.write,
+		Inflation:   lfu.inflation,
+		Halvings:    lfu.halvings,
+		EvictBucket: lfu.evictBucket,
+	}
+	for bucket := lfu.buckets.next; bucket != lfu.buckets; bucket = bucket.next {
+		bucketState := lfuBucketState{Priority: bucket.priority, Blocks: make([]lfuBlockState, 0, bucket.len)}
+		for node := bucket.root.next; node != &bucket.root; node = node.next {
+			bucketState.Blocks = append(bucketState.Blocks, lfuBlockState{LBA: node.lba, Freq: node.freq})
+		}
+		state.Buckets = append(state.Buckets, bucketState)
+	}
+	return enc.Encode(state)
+}
+
+func (lfu *LFU) Restore(dec *gob.Decoder) error {
+	var state lfuState
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	restored := NewLFU(lfu.maxlen)
+	for i, bucketState := range state.Buckets {
+		if i > 0 && bucketState.Priority <= state.Buckets[i-1].Priority {
+			return fmt.Errorf("lfu: snapshot buckets are not sorted")
+		}
+		// bucket disambung di akhir dan blok di belakang bucket sesuai urutan
+		bucket := restored.newBucket(bucketState.Priority)
+		bucket.prev = restored.buckets.prev
+		bucket.next = restored.buckets
+		restored.buckets.prev.next = bucket
+		restored.buckets.prev = bucket
+		for _, block := range bucketState.Blocks {
+			if _, ok := restored.tlba[block.LBA]; ok {
+				return fmt.Errorf("lfu: snapshot has duplicate block %d", block.LBA)
+			}
+			node := &Node{lba: block.LBA, freq: block.Freq, priority: bucket.priority, bucket: bucket}
+			node.prev = bucket.root.prev
+			node.next = &bucket.root
+			bucket.root.prev.next = node
+			bucket.root.prev = node
+			bucket.len++
+			restored.tlba[block.LBA] = node
+		}
+	}
+	if len(restored.tlba)+state.Available != lfu.maxlen {
+		return fmt.Errorf("lfu: snapshot holds %d blocks and %d free for cache size %d", len(restored.tlba), state.Available, lfu.maxlen)
+	}
+	lfu.available = state.Available
+	lfu.totalaccess = state.TotalAccess
+	lfu.hit = state.Hit
+	lfu.miss = state.Miss
+	lfu.pagefault = state.PageFault
+	lfu.write = state.Write
+	lfu.inflation = state.Inflation
+	lfu.halvings = state.Halvings
+	lfu.evictBucket = state.EvictBucket
+	lfu.tlba = restored.tlba
+	lfu.buckets = restored.buckets
+	lfu.bucketCount = restored.bucketCount
+	lfu.freeBuckets = nil
+	return nil
+}
@@ -0,0 +1,118 @@
+package lfu
+
+import (
+	"fmt"
+	"testing"
+
+	"ixtza/ajk/wec/simulator"
+	"ixtza/ajk/wec/synthetic"
+)
+
+// referenceLFU adalah LFU paling sederhana: prioritas dan waktu masuk ke
+// prioritas tersebut per blok, korban dicari secara linear (prioritas
+// terkecil lalu yang paling lama)
+type referenceLFU struct {
+	size      int
+	aging     string
+	blocks    map[int]*referenceBlock
+	inflation int
+	clock     int
+	hit       int
+	write     int
+}
+
+type referenceBlock struct {
+	freq     int
+	priority int
+	since    int
+}
+
+func newReferenceLFU(size int, aging string) *referenceLFU {
+	return &referenceLFU{size: size, aging: aging, blocks: map[int]*referenceBlock{}}
+}
+
+func (ref *referenceLFU) get(trace simulator.Trace) {
+	ref.clock++
+	if block, ok := ref.blocks[trace.Addr]; ok {
+		ref.hit++
+		if trace.Op == "W" {
+			ref.write++
+		}
+		if ref.aging == AgingNone && block.freq >= MAXFREQ {
+			return
+		}
+		block.freq++
+		block.priority = ref.priority(block.freq)
+		block.since = ref.clock
+		return
+	}
+	ref.write++
+	if len(ref.blocks) == ref.size {
+		victim := -1
+		var oldest *referenceBlock
+		for lba, block := range ref.blocks {
+			if oldest == nil || block.priority < oldest.priority || (block.priority == oldest.priority && block.since < oldest.since) {
+				victim, oldest = lba, block
+			}
+		}
+		if ref.aging == AgingDynamic {
+			ref.inflation = oldest.priority
+		}
+		delete(ref.blocks, victim)
+	}
+	ref.blocks[trace.Addr] = &referenceBlock{freq: 1, priority: ref.priority(1), since: ref.clock}
+}
+
+func (ref *referenceLFU) priority(freq int) int {
+	if ref.aging == AgingDynamic {
+		return freq + ref.inflation
+	}
+	return freq
+}
+
+func TestMatchesReference(t *testing.T) {
+	for _, workload := range synthetic.Workloads {
+		traces, err := synthetic.Trace(workload, 50000, 5000, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, aging := range []string{AgingNone, AgingUnbounded, AgingDynamic} {
+			for _, cache := range []int{1, 64, 1000, 5000} {
+				t.Run(fmt.Sprintf("%s-%s-%d", workload, aging, cache), func(t *testing.T) {
+					lfu, err := NewLFUWithAging(cache, aging, 0)
+					if err != nil {
+						t.Fatal(err)
+					}
+					ref := newReferenceLFU(cache, aging)
+					for _, trace := range traces {
+						lfu.Get(trace)
+						ref.get(trace)
+					}
+					if lfu.hit != ref.hit || lfu.write != ref.write {
+						t.Fatalf("hit %d write %d, reference hit %d write %d", lfu.hit, lfu.write, ref.hit, ref.write)
+					}
+					if lfu.hit+lfu.miss != len(traces) {
+						t.Fatalf("hit %d + miss %d != %d requests", lfu.hit, lfu.miss, len(traces))
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkLFU(b *testing.B) {
+	for _, workload := range synthetic.Workloads {
+		traces, err := synthetic.Trace(workload, 1000000, 100000, 0.3, 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(workload, func(b *testing.B) {
+			lfu := NewLFU(10000)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				lfu.Get(traces[i%len(traces)])
+			}
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package lirs
 
 import (
+	"encoding/gob"
 	"errors"
 	"fmt"
-	"log"
 	"os"
+	"sort"
 	"time"
+	"unsafe"
 
 	"ixtza/ajk/wec/simulator"
 	// "github.com/esaiy/golang-lirs/simulator"
@@ -22,16 +24,64 @@ type LIRS struct {
 	orderedStack *orderedmap.OrderedMap
 	orderedList  *orderedmap.OrderedMap
 	LIR          map[interface{}]int
-	HIR          map[interface{}]int
 	// cache        map[interface{}]bool
+
+	// blok HIR non-resident di stack, urut sesuai waktu keluar dari cache;
+	// bersama orderedList (HIR resident) menggantikan map HIR yang tumbuh
+	// mengikuti footprint trace
+	nonResident       *orderedmap.OrderedMap
+	maxNonResident    int
+	nonResidentPruned int
+	maxStackSize      int
+
+	requestCount int
+	simulator.Emitter
+}
+
+// lirsState adalah isi snapshot LIRS; Stack, List dan NonResident dari
+// yang paling lama, LIR terurut alamat
+type lirsState struct {
+	Hit               int
+	Miss              int
+	WriteCount        int
+	NonResidentPruned int
+	MaxStackSize      int
+	RequestCount      int
+	Stack             []int
+	List              []int
+	NonResident       []int
+	LIR               []int
 }
 
-func NewLIRS(cacheSize, HIRSize int) *LIRS {
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "lirs",
+		Description: "Low Inter-reference Recency Set",
+		Admission:   true,
+		Params: []simulator.Param{
+			{Name: "lirs-hir", Kind: simulator.ParamInt, Default: 1, Usage: "persentase partisi HIR terhadap cache LIRS (paling sedikit satu blok)"},
+			{Name: "lirs-max-nonresident", Kind: simulator.ParamInt, Default: 0, Usage: "batas blok HIR non-resident di stack LIRS (0 = tanpa batas)"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewLIRSWithBound(cacheSize, params.Int("lirs-hir"), params.Int("lirs-max-nonresident"))
+		},
+	})
+}
+
+// NewLIRS membuat LIRS dengan partisi HIR sebesar HIRSize persen cache
+func NewLIRS(cacheSize, HIRSize int) (*LIRS, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("lirs: cache size must be positive, got %d", cacheSize)
+	}
 	if HIRSize > 100 || HIRSize < 0 {
-		log.Fatal("HIRSize must be between 0 and 100")
+		return nil, fmt.Errorf("lirs: HIR size must be between 0 and 100, got %d", HIRSize)
 	}
 	LIRCapacity := (100 - HIRSize) * cacheSize / 100
 	HIRCapacity := HIRSize * cacheSize / 100
+	// tanpa tempat HIR resident, addToList tidak pernah mengeluarkan blok
+	if HIRCapacity < 1 {
+		return nil, fmt.Errorf("lirs: HIR size %d%% leaves no HIR block for cache size %d", HIRSize, cacheSize)
+	}
 	return &LIRS{
 		cacheSize:    cacheSize,
 		LIRSize:      LIRCapacity,
@@ -42,14 +92,30 @@ func NewLIRS(cacheSize, HIRSize int) *LIRS {
 		orderedStack: orderedmap.NewOrderedMap(),
 		orderedList:  orderedmap.NewOrderedMap(),
 		LIR:          make(map[interface{}]int, LIRCapacity),
-		HIR:          make(map[interface{}]int, HIRCapacity),
 		// cache:        make(map[interface{}]bool, cacheSize),
+		nonResident: orderedmap.NewOrderedMap(),
+	}, nil
+}
+
+// NewLIRSWithBound membatasi jumlah blok HIR non-resident di stack seperti
+// varian LIRS terbatas; maxNonResident 0 berarti tanpa batas
+func NewLIRSWithBound(cacheSize, HIRSize, maxNonResident int) (*LIRS, error) {
+	if maxNonResident < 0 {
+		return nil, fmt.Errorf("lirs: max non-resident must not be negative, got %d", maxNonResident)
+	}
+	LIRSObject, err := NewLIRS(cacheSize, HIRSize)
+	if err != nil {
+		return nil, err
 	}
+	LIRSObject.maxNonResident = maxNonResident
+	return LIRSObject, nil
 }
 
 func (LIRSObject *LIRS) Get(trace simulator.Trace) (err error) {
 	block := trace.Addr
 	op := trace.Op
+	LIRSObject.requestCount++
+	defer LIRSObject.boundStack()
 	// if op == "W" {
 	// 	LIRSObject.writeCount++
 	// }
@@ -65,6 +131,11 @@ func (LIRSObject *LIRS) Get(trace simulator.Trace) (err error) {
 			LIRSObject.hit += 1
 			// Tambahan
 			LIRSObject.writeCount--
+			LIRSObject.emit(simulator.EventHit, block, simulator.TierLIR)
+		} else {
+			LIRSObject.emit(simulator.EventMiss, block, simulator.TierLIR)
+			LIRSObject.emit(simulator.EventInsert, block, simulator.TierLIR)
+			LIRSObject.emit(simulator.EventSSDWrite, block, simulator.TierLIR)
 		}
 		LIRSObject.addToStack(block)
 		LIRSObject.makeLIR(block)
@@ -77,13 +148,15 @@ func (LIRSObject *LIRS) Get(trace simulator.Trace) (err error) {
 		// Tambahan 2
 		if op == "W" {
 			LIRSObject.writeCount++
+			LIRSObject.emit(simulator.EventSSDWrite, block, simulator.TierLIR)
 		}
 	} else if _, ok := LIRSObject.orderedList.Get(block); ok {
 		// hit, block is HIR resident
-		LIRSObject.handleHIRResidentBlock(block)
+		tier := LIRSObject.handleHIRResidentBlock(block)
 		// Tambahan 2
 		if op == "W" {
 			LIRSObject.writeCount++
+			LIRSObject.emit(simulator.EventSSDWrite, block, tier)
 		}
 	} else {
 		// miss, blok is HIR non resident
@@ -103,19 +176,28 @@ cache miss : %v
 hit ratio : %v
 list size : %v
 stack size : %v
+peak stack size : %v
+non-resident entries : %v
+non-resident limit : %v
+non-resident pruned : %v
 lir capacity: %v
 hir capacity: %v
 write count : %v
 write efficiency : %v
 duration : %v
 !LIRS|%v|%v|%v
-`, LIRSObject.cacheSize, LIRSObject.hit, LIRSObject.miss, hitRatio, LIRSObject.orderedList.Len(), LIRSObject.orderedStack.Len(), LIRSObject.LIRSize, LIRSObject.HIRSize, LIRSObject.writeCount,LIRSObject.hit/LIRSObject.writeCount, duration.Seconds(), LIRSObject.cacheSize, LIRSObject.hit, LIRSObject.hit+LIRSObject.miss)
+`, LIRSObject.cacheSize, LIRSObject.hit, LIRSObject.miss, hitRatio, LIRSObject.orderedList.Len(), LIRSObject.orderedStack.Len(), LIRSObject.maxStackSize, LIRSObject.nonResident.Len(), LIRSObject.maxNonResident, LIRSObject.nonResidentPruned, LIRSObject.LIRSize, LIRSObject.HIRSize, LIRSObject.writeCount,LIRSObject.hit/LIRSObject.writeCount, duration.Seconds(), LIRSObject.cacheSize, LIRSObject.hit, LIRSObject.hit+LIRSObject.miss)
 	_, err = file.WriteString(result)
 	return err
 }
 
+func (LIRSObject *LIRS) emit(kind simulator.EventKind, block int, tier simulator.Tier) {
+	LIRSObject.Emit(kind, block, tier, LIRSObject.requestCount)
+}
+
 func (LIRSObject *LIRS) handleLIRBlock(block int) (err error) {
 	LIRSObject.hit += 1
+	LIRSObject.emit(simulator.EventHit, block, simulator.TierLIR)
 	key, _, ok := LIRSObject.orderedStack.GetFirst()
 	if !ok {
 		return errors.New("orderedStack is empty")
@@ -129,22 +211,30 @@ func (LIRSObject *LIRS) handleLIRBlock(block int) (err error) {
 	return nil
 }
 
-func (LIRSObject *LIRS) handleHIRResidentBlock(block int) {
+// handleHIRResidentBlock mengembalikan partisi blok setelah diakses
+func (LIRSObject *LIRS) handleHIRResidentBlock(block int) (tier simulator.Tier) {
 	LIRSObject.hit += 1
+	LIRSObject.emit(simulator.EventHit, block, simulator.TierHIR)
+	tier = simulator.TierHIR
 	if _, ok := LIRSObject.orderedStack.Get(block); ok {
 		// block is in stack, move to LIR
 		LIRSObject.makeLIR(block)
 		LIRSObject.removeFromList(block)
+		LIRSObject.EmitMove(simulator.EventPromote, block, simulator.TierHIR, simulator.TierLIR, LIRSObject.requestCount)
 		LIRSObject.stackPrunning(true)
+		tier = simulator.TierLIR
 	} else {
 		// block is not in stack, move to end of list
 		LIRSObject.orderedList.MoveLast(block)
 	}
 	LIRSObject.addToStack(block)
+	return tier
 }
 
 func (LIRSObject *LIRS) handleHIRNonResidentBlock(block int) {
+	LIRSObject.nonResident.Delete(block)
 	LIRSObject.miss += 1
+	LIRSObject.emit(simulator.EventMiss, block, simulator.TierHIR)
 	// Tambahan
 	LIRSObject.writeCount++
 	LIRSObject.addToList(block)
@@ -152,9 +242,13 @@ func (LIRSObject *LIRS) handleHIRNonResidentBlock(block int) {
 		// block is in stack, move to LIR
 		LIRSObject.makeLIR(block)
 		LIRSObject.removeFromList(block)
+		LIRSObject.emit(simulator.EventInsert, block, simulator.TierLIR)
+		LIRSObject.emit(simulator.EventSSDWrite, block, simulator.TierLIR)
 		LIRSObject.stackPrunning(true)
 	} else {
 		LIRSObject.makeHIR(block)
+		LIRSObject.emit(simulator.EventInsert, block, simulator.TierHIR)
+		LIRSObject.emit(simulator.EventSSDWrite, block, simulator.TierHIR)
 	}
 	LIRSObject.addToStack(block)
 }
@@ -168,8 +262,14 @@ func (LIRSObject *LIRS) addToStack(block int) {
 }
 
 func (LIRSObject *LIRS) addToList(block int) {
-	if LIRSObject.orderedList.Len() == LIRSObject.HIRSize {
-		LIRSObject.orderedList.PopFirst()
+	if LIRSObject.orderedList.Len() >= LIRSObject.HIRSize {
+		key, _, ok := LIRSObject.orderedList.PopFirst()
+		if ok {
+			LIRSObject.emit(simulator.EventEvict, key.(int), simulator.TierHIR)
+		}
+		if _, inStack := LIRSObject.orderedStack.Get(key); ok && inStack {
+			LIRSObject.nonResident.Set(key, 1)
+		}
 	}
 	LIRSObject.orderedList.Set(block, 1)
 }
@@ -179,13 +279,12 @@ func (LIRSObject *LIRS) removeFromList(block int) {
 }
 
 func (LIRSObject *LIRS) makeLIR(block int) {
+	LIRSObject.nonResident.Delete(block)
 	LIRSObject.LIR[block] = 1
 	LIRSObject.removeFromList(block)
-	delete(LIRSObject.HIR, block)
 }
 
 func (LIRSObject *LIRS) makeHIR(block int) {
-	LIRSObject.HIR[block] = 1
 	delete(LIRSObject.LIR, block)
 }
 
@@ -194,7 +293,9 @@ func (LIRSObject *LIRS) stackPrunning(removeLIR bool) (err error) {
 	if !ok {
 		return errors.New("orderedStack is empty")
 	}
+	LIRSObject.nonResident.Delete(key)
 	if removeLIR {
+		LIRSObject.EmitMove(simulator.EventDemote, key.(int), simulator.TierLIR, simulator.TierHIR, LIRSObject.requestCount)
 		LIRSObject.makeHIR(key.(int))
 		LIRSObject.orderedList.Set(key, 1)
 		LIRSObject.orderedList.MoveLast(key)
@@ -206,6 +307,126 @@ func (LIRSObject *LIRS) stackPrunning(removeLIR bool) (err error) {
 			break
 		}
 		LIRSObject.orderedStack.PopFirst()
+		LIRSObject.nonResident.Delete(k)
+	}
+	return nil
+}
+
+// boundStack membuang blok non-resident paling lama dari stack jika
+// jumlahnya melebihi maxNonResident, lalu mencatat ukuran stack puncak
+func (LIRSObject *LIRS) boundStack() {
+	for LIRSObject.maxNonResident > 0 && LIRSObject.nonResident.Len() > LIRSObject.maxNonResident {
+		key, _, _ := LIRSObject.nonResident.PopFirst()
+		LIRSObject.orderedStack.Delete(key)
+		LIRSObject.nonResidentPruned++
+	}
+	if LIRSObject.orderedStack.Len() > LIRSObject.maxStackSize {
+		LIRSObject.maxStackSize = LIRSObject.orderedStack.Len()
+	}
+}
+
+func (LIRSObject *LIRS) Contains(block int) bool {
+	if _, ok := LIRSObject.LIR[block]; ok {
+		return true
+	}
+	_, ok := LIRSObject.orderedList.Get(block)
+	return ok
+}
+
+func (LIRSObject *LIRS) Victim() (block int, ok bool) {
+	if len(LIRSObject.LIR) < LIRSObject.LIRSize {
+		return 0, false
+	}
+	if LIRSObject.orderedList.Len() < LIRSObject.HIRSize {
+		return 0, false
+	}
+	key, _, ok := LIRSObject.orderedList.GetFirst()
+	if !ok {
+		return 0, false
+	}
+	return key.(int), true
+}
+
+func (LIRSObject *LIRS) Misses() int {
+	return LIRSObject.miss
+}
+
+func (LIRSObject *LIRS) Capacity() int {
+	return LIRSObject.cacheSize
+}
+
+func (LIRSObject *LIRS) Metadata() simulator.Metadata {
+	// setiap blok dihitung sekali: LIR (map dan stack), HIR resident (list,
+	// mungkin juga stack) dan HIR non-resident (stack); tidak ada map HIR
+	// lain yang tumbuh mengikuti footprint trace
+	return simulator.Metadata{
+		Tracked:       len(LIRSObject.LIR) + LIRSObject.orderedList.Len() + LIRSObject.nonResident.Len(),
+		Ghost:         LIRSObject.nonResident.Len(),
+		BytesPerEntry: simulator.OrderedMapEntryBytes + simulator.InterfaceMapBytes + int(unsafe.Sizeof(int(0))),
+	}
+}
+
+func (LIRSObject *LIRS) Snapshot(enc *gob.Encoder) error {
+	return enc.Encode(lirsState{
+		Hit:               LIRSObject.hit,
+		Miss:              LIRSObject.miss,
+		WriteCount:        LIRSObject.writeCount,
+		NonResidentPruned: LIRSObject.nonResidentPruned,
+		MaxStackSize:      LIRSObject.maxStackSize,
+		RequestCount:      LIRSObject.requestCount,
+		Stack:             orderedKeys(LIRSObject.orderedStack),
+		List:              orderedKeys(LIRSObject.orderedList),
+		NonResident:       orderedKeys(LIRSObject.nonResident),
+		LIR:               sortedKeys(LIRSObject.LIR),
+	})
+}
+
+func (LIRSObject *LIRS) Restore(dec *gob.Decoder) error {
+	var state lirsState
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	if len(state.List) > LIRSObject.HIRSize {
+		return fmt.Errorf("lirs: snapshot holds %d HIR resident blocks for capacity %d", len(state.List), LIRSObject.HIRSize)
+	}
+	LIRSObject.hit = state.Hit
+	LIRSObject.miss = state.Miss
+	LIRSObject.writeCount = state.WriteCount
+	LIRSObject.nonResidentPruned = state.NonResidentPruned
+	LIRSObject.maxStackSize = state.MaxStackSize
+	LIRSObject.requestCount = state.RequestCount
+	LIRSObject.orderedStack = newOrderedMap(state.Stack)
+	LIRSObject.orderedList = newOrderedMap(state.List)
+	LIRSObject.nonResident = newOrderedMap(state.NonResident)
+	LIRSObject.LIR = make(map[interface{}]int, LIRSObject.LIRSize)
+	for _, block := range state.LIR {
+		LIRSObject.LIR[block] = 1
 	}
 	return nil
 }
+
+func orderedKeys(om *orderedmap.OrderedMap) []int {
+	keys := make([]int, 0, om.Len())
+	iter := om.Iter()
+	for key, _, ok := iter.Next(); ok; key, _, ok = iter.Next() {
+		keys = append(keys, key.(int))
+	}
+	return keys
+}
+
+func sortedKeys(m map[interface{}]int) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key.(int))
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func newOrderedMap(keys []int) *orderedmap.OrderedMap {
+	om := orderedmap.NewOrderedMap()
+	for _, key := range keys {
+		om.Set(key, 1)
+	}
+	return om
+}
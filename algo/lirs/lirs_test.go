@@ -0,0 +1,43 @@
+package lirs
+
+import (
+	"fmt"
+	"testing"
+
+	"ixtza/ajk/wec/synthetic"
+)
+
+func TestRejectsEmptyHIR(t *testing.T) {
+	for _, config := range []struct{ cache, hir int }{{50, 1}, {99, 1}, {5000, 0}} {
+		if _, err := NewLIRS(config.cache, config.hir); err == nil {
+			t.Errorf("cache %d, HIR %d%%: no error", config.cache, config.hir)
+		}
+	}
+}
+
+// TestHIRBounded memastikan jumlah blok HIR resident tidak melebihi
+// partisi HIR, termasuk partisi satu blok
+func TestHIRBounded(t *testing.T) {
+	for _, workload := range synthetic.Workloads {
+		traces, err := synthetic.Trace(workload, 50000, 5000, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, config := range []struct{ cache, hir int }{{100, 1}, {150, 1}, {1000, 5}} {
+			t.Run(fmt.Sprintf("%s-%d-%d", workload, config.cache, config.hir), func(t *testing.T) {
+				lirs, err := NewLIRS(config.cache, config.hir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for index, trace := range traces {
+					if err := lirs.Get(trace); err != nil {
+						t.Fatal(err)
+					}
+					if lirs.orderedList.Len() > lirs.HIRSize {
+						t.Fatalf("request %d: %d HIR resident for HIR size %d", index, lirs.orderedList.Len(), lirs.HIRSize)
+					}
+				}
+			})
+		}
+	}
+}
@@ -1,24 +1,16 @@
 package lru
 
 import (
-	"container/list"
+	"encoding/gob"
 	"fmt"
 	"os"
 	"time"
 
+	"ixtza/ajk/wec/algo/internal/queue"
 	"ixtza/ajk/wec/simulator"
-	// "github.com/esaiy/golang-lirs/simulator"
-	"github.com/petar/GoLLRB/llrb"
 )
 
 type (
-	Node struct {
-		lba        int
-		lastaccess int
-		op         string
-		elem       *list.Element
-	}
-
 	LRU struct {
 		maxlen      int
 		available   int
@@ -28,15 +20,37 @@ type (
 		pagefault   int
 		write       int
 
-		tlba    *llrb.LLRB
-		lrulist *list.List
-	}
+		// indeks hash + list intrusif, entri yang dievict dipakai ulang
+		lrulist *queue.Queue[struct{}]
 
-	NodeLba Node
+		simulator.Emitter
+	}
 )
 
-func (x *NodeLba) Less(than llrb.Item) bool {
-	return x.lba < than.(*NodeLba).lba
+// lruState adalah isi snapshot LRU; Order dari yang terbaru ke yang
+// paling lama
+type lruState struct {
+	Available   int
+	TotalAccess int
+	Hit         int
+	Miss        int
+	PageFault   int
+	Write       int
+	Order       []int
+}
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "lru",
+		Description: "Least Recently Used",
+		Admission:   true,
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			if cacheSize <= 0 {
+				return nil, fmt.Errorf("lru: cache size must be positive, got %d", cacheSize)
+			}
+			return NewLRU(cacheSize), nil
+		},
+	})
 }
 
 func NewLRU(cacheSize int) *LRU {
@@ -47,62 +61,43 @@ func NewLRU(cacheSize int) *LRU {
 		hit:         0,
 		miss:        0,
 		pagefault:   0,
-		lrulist:     list.New(),
-		tlba:        llrb.New(),
+		lrulist:     queue.New[struct{}](),
 	}
 	return lru
 }
 
-func (lru *LRU) put(data *NodeLba) (exists bool) {
-	var el *list.Element
-	kk := new(NodeLba)
-
-	node := lru.tlba.Get((*NodeLba)(data))
-	if node != nil {
+func (lru *LRU) put(lba int, op string) (exists bool) {
+	if el, ok := lru.lrulist.Get(lba); ok {
 		lru.hit++
-		dd := node.(*NodeLba) // shortcut saja
-		if data.op == "W" {
+		lru.Emit(simulator.EventHit, lba, simulator.TierSSD, lru.totalaccess)
+		if op == "W" {
 			lru.write++
+			lru.Emit(simulator.EventSSDWrite, lba, simulator.TierSSD, lru.totalaccess)
 		}
-		lru.lrulist.Remove(dd.elem)
-		el = lru.lrulist.PushFront(dd.elem.Value)
-		dd.elem = el // update elem
+		lru.lrulist.MoveToFront(el)
 		return true
-	} else { // not exist
-		lru.miss++
-		lru.write++
-		if lru.available > 0 {
-			lru.available--
-			el = lru.lrulist.PushFront(data)
-			lru.tlba.InsertNoReplace(data)
-			data.elem = el
-		} else {
-			lru.pagefault++
-
-			// delete dulu
-			el = lru.lrulist.Back()
-			lba := el.Value.(*NodeLba).lba
-			kk.lba = lba
-			lru.tlba.Delete(kk) // hapus dah
-			lru.lrulist.Remove(el)
-
-			// masukkan lagi
-			el = lru.lrulist.PushFront(data)
-			data.elem = el
-			lru.tlba.InsertNoReplace(data)
-		}
-		return false
 	}
+
+	lru.miss++
+	lru.Emit(simulator.EventMiss, lba, simulator.TierSSD, lru.totalaccess)
+	lru.write++
+	if lru.available > 0 {
+		lru.available--
+	} else {
+		lru.pagefault++
+		victim := lru.lrulist.PopBack()
+		lru.Emit(simulator.EventEvict, victim.Key, simulator.TierSSD, lru.totalaccess)
+		lru.lrulist.Release(victim)
+	}
+	lru.lrulist.PushFront(lba, struct{}{})
+	lru.Emit(simulator.EventInsert, lba, simulator.TierSSD, lru.totalaccess)
+	lru.Emit(simulator.EventSSDWrite, lba, simulator.TierSSD, lru.totalaccess)
+	return false
 }
 
 func (lru *LRU) Get(trace simulator.Trace) (err error) {
 	lru.totalaccess++
-	obj := new(NodeLba)
-	obj.lba = trace.Addr
-	obj.op = trace.Op
-	obj.lastaccess = lru.totalaccess
-
-	lru.put(obj)
+	lru.put(trace.Addr, trace.Op)
 
 	return nil
 }
@@ -116,10 +111,81 @@ func (lru LRU) PrintToFile(file *os.File, timeStart time.Time) (err error) {
 	file.WriteString(fmt.Sprintf("ssd write: %d\n", lru.write))
 	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (lru.hit / lru.write)))
 	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(lru.hit)/float64(lru.totalaccess))*100))
-	file.WriteString(fmt.Sprintf("tlba size : %d\n", lru.tlba.Len()))
+	file.WriteString(fmt.Sprintf("tlba size : %d\n", lru.lrulist.Len()))
 	file.WriteString(fmt.Sprintf("list size : %d\n", lru.lrulist.Len()))
 
 	file.WriteString(fmt.Sprintf("!LRU|%d|%d|%d\n", lru.maxlen, lru.hit, lru.write))
 
 	return nil
 }
+
+func (lru *LRU) Contains(address int) bool {
+	return lru.lrulist.Contains(address)
+}
+
+func (lru *LRU) Victim() (address int, ok bool) {
+	if lru.available > 0 {
+		return 0, false
+	}
+	el := lru.lrulist.Back()
+	if el == nil {
+		return 0, false
+	}
+	return el.Key, true
+}
+
+func (lru *LRU) Misses() int {
+	return lru.miss
+}
+
+func (lru *LRU) Capacity() int {
+	return lru.maxlen
+}
+
+func (lru *LRU) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       lru.lrulist.Len(),
+		BytesPerEntry: lru.lrulist.EntryBytes(),
+	}
+}
+
+func (lru *LRU) Snapshot(enc *gob.Encoder) error {
+	state := lruState{
+		Available:   lru.available,
+		TotalAccess: lru.totalaccess,
+		Hit:         lru.hit,
+		Miss:        lru.miss,
+		PageFault:   lru.pagefault,
+		Write:       lru.write,
+		Order:       make([]int, 0, lru.lrulist.Len()),
+	}
+	for el := lru.lrulist.Front(); el != nil; el = el.Next() {
+		state.Order = append(state.Order, el.Key)
+	}
+	return enc.Encode(state)
+}
+
+func (lru *LRU) Restore(dec *gob.Decoder) error {
+	var state lruState
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	if len(state.Order)+state.Available != lru.maxlen {
+		return fmt.Errorf("lru: snapshot holds %d blocks and %d free for cache size %d", len(state.Order), state.Available, lru.maxlen)
+	}
+	lrulist := queue.New[struct{}]()
+	for _, lba := range state.Order {
+		lrulist.PushBack(lba, struct{}{})
+	}
+	if lrulist.Len() != len(state.Order) {
+		return fmt.Errorf("lru: snapshot has duplicate blocks")
+	}
+	lru.available = state.Available
+	lru.totalaccess = state.TotalAccess
+	lru.hit = state.Hit
+	lru.miss = state.Miss
+	lru.pagefault = state.PageFault
+	lru.write = state.Write
+	lru.lrulist = lrulist
+	return nil
+}
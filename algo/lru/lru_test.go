@@ -0,0 +1,84 @@
+package lru
+
+import (
+	"fmt"
+	"testing"
+
+	"ixtza/ajk/wec/simulator"
+	"ixtza/ajk/wec/synthetic"
+)
+
+// referenceLRU adalah LRU paling sederhana: waktu akses terakhir per blok
+// dan pencarian linear korban
+type referenceLRU struct {
+	size       int
+	lastAccess map[int]int
+	clock      int
+	hit        int
+	write      int
+}
+
+func (ref *referenceLRU) get(trace simulator.Trace) {
+	ref.clock++
+	if _, ok := ref.lastAccess[trace.Addr]; ok {
+		ref.hit++
+		if trace.Op == "W" {
+			ref.write++
+		}
+		ref.lastAccess[trace.Addr] = ref.clock
+		return
+	}
+	ref.write++
+	if len(ref.lastAccess) == ref.size {
+		victim, oldest := 0, ref.clock
+		for lba, access := range ref.lastAccess {
+			if access < oldest {
+				victim, oldest = lba, access
+			}
+		}
+		delete(ref.lastAccess, victim)
+	}
+	ref.lastAccess[trace.Addr] = ref.clock
+}
+
+func TestMatchesReference(t *testing.T) {
+	for _, workload := range synthetic.Workloads {
+		traces, err := synthetic.Trace(workload, 50000, 5000, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cache := range []int{1, 64, 1000, 5000} {
+			t.Run(fmt.Sprintf("%s-%d", workload, cache), func(t *testing.T) {
+				lru := NewLRU(cache)
+				ref := &referenceLRU{size: cache, lastAccess: map[int]int{}}
+				for _, trace := range traces {
+					lru.Get(trace)
+					ref.get(trace)
+				}
+				if lru.hit != ref.hit || lru.write != ref.write {
+					t.Fatalf("hit %d write %d, reference hit %d write %d", lru.hit, lru.write, ref.hit, ref.write)
+				}
+				if lru.hit+lru.miss != len(traces) {
+					t.Fatalf("hit %d + miss %d != %d requests", lru.hit, lru.miss, len(traces))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkLRU(b *testing.B) {
+	for _, workload := range synthetic.Workloads {
+		traces, err := synthetic.Trace(workload, 1000000, 100000, 0.3, 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(workload, func(b *testing.B) {
+			lru := NewLRU(10000)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				lru.Get(traces[i%len(traces)])
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package lruk
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"github.com/tidwall/btree"
+	"ixtza/ajk/wec/simulator"
+)
+
+type (
+	Page struct {
+		lba      int
+		hist     []int // hist[0] referensi terakhir tak berkorelasi, hist[K-1] ke-K
+		last     int
+		resident bool
+		elem     *list.Element
+	}
+
+	// LRUK mengikuti LRU-K (O'Neil, O'Neil, Weikum 1993). Korban adalah
+	// halaman di luar correlated reference period dengan backward
+	// K-distance terbesar, yaitu hist[K-1] terkecil.
+	LRUK struct {
+		maxlen          int
+		k               int
+		crp             int
+		historySize     int
+		totalaccess     int
+		hit             int
+		miss            int
+		pagefault       int
+		write           int
+		correlatedCount int
+
+		pages    map[int]*Page
+		resident *btree.BTreeG[*Page]
+		history  *list.List
+	}
+)
+
+func lessPage(a, b *Page) bool {
+	ka, kb := a.hist[len(a.hist)-1], b.hist[len(b.hist)-1]
+	if ka != kb {
+		return ka < kb
+	}
+	if a.hist[0] != b.hist[0] {
+		return a.hist[0] < b.hist[0]
+	}
+	return a.lba < b.lba
+}
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "lruk",
+		Description: "LRU-K",
+		Params: []simulator.Param{
+			{Name: "lruk-k", Kind: simulator.ParamInt, Default: 2, Usage: "nilai K pada LRU-K"},
+			{Name: "lruk-crp", Kind: simulator.ParamInt, Default: 0, Usage: "correlated reference period (request)"},
+			{Name: "lruk-history", Kind: simulator.ParamInt, Default: 0, Usage: "jumlah riwayat halaman non-resident (0 = ukuran cache)"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			historySize := params.Int("lruk-history")
+			if historySize == 0 {
+				historySize = cacheSize
+			}
+			return NewLRUK(cacheSize, params.Int("lruk-k"), params.Int("lruk-crp"), historySize)
+		},
+	})
+}
+
+// NewLRUK membuat LRU-K dengan correlated reference period crp (request)
+// dan riwayat halaman non-resident paling banyak historySize
+func NewLRUK(cacheSize, k, crp, historySize int) (*LRUK, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("lruk: cache size must be positive, got %d", cacheSize)
+	}
+	if k < 1 {
+		return nil, fmt.Errorf("lruk: K must be positive, got %d", k)
+	}
+	if crp < 0 || historySize < 0 {
+		return nil, fmt.Errorf("lruk: correlated reference period and history size must not be negative")
+	}
+	return &LRUK{
+		maxlen:      cacheSize,
+		k:           k,
+		crp:         crp,
+		historySize: historySize,
+		pages:       make(map[int]*Page, cacheSize),
+		resident:    btree.NewBTreeG[*Page](lessPage),
+		history:     list.New(),
+	}, nil
+}
+
+func (lruk *LRUK) evict() {
+	t := lruk.totalaccess
+	var victim *Page
+	lruk.resident.Scan(func(page *Page) bool {
+		if t-page.last > lruk.crp {
+			victim = page
+			return false
+		}
+		return true
+	})
+	if victim == nil {
+		// semua halaman masih dalam periode korelasi, ambil yang terkecil
+		victim, _ = lruk.resident.Min()
+	}
+	lruk.resident.Delete(victim)
+	victim.resident = false
+
+	if lruk.historySize <= 0 {
+		delete(lruk.pages, victim.lba)
+		return
+	}
+	victim.elem = lruk.history.PushBack(victim)
+	if lruk.history.Len() > lruk.historySize {
+		old := lruk.history.Remove(lruk.history.Front()).(*Page)
+		delete(lruk.pages, old.lba)
+	}
+}
+
+func (lruk *LRUK) Get(trace simulator.Trace) (err error) {
+	lruk.totalaccess++
+	t := lruk.totalaccess
+
+	page, ok := lruk.pages[trace.Addr]
+	if ok && page.resident {
+		lruk.hit++
+		if trace.Op == "W" {
+			lruk.write++
+		}
+		if t-page.last <= lruk.crp {
+			lruk.correlatedCount++
+			page.last = t
+			return nil
+		}
+		// periode korelasi sebelumnya ditutup, geser riwayat
+		lruk.resident.Delete(page)
+		correlation := page.last - page.hist[0]
+		for i := lruk.k - 1; i > 0; i-- {
+			if page.hist[i-1] != 0 {
+				page.hist[i] = page.hist[i-1] + correlation
+			}
+		}
+		page.hist[0] = t
+		page.last = t
+		lruk.resident.Set(page)
+		return nil
+	}
+
+	lruk.miss++
+	lruk.write++
+	if lruk.resident.Len() >= lruk.maxlen {
+		lruk.pagefault++
+		lruk.evict()
+	}
+
+	// riwayat halaman ini bisa saja ikut terbuang saat evict
+	page, ok = lruk.pages[trace.Addr]
+	if ok {
+		lruk.history.Remove(page.elem)
+		page.elem = nil
+		for i := lruk.k - 1; i > 0; i-- {
+			page.hist[i] = page.hist[i-1]
+		}
+	} else {
+		page = &Page{lba: trace.Addr, hist: make([]int, lruk.k)}
+		lruk.pages[trace.Addr] = page
+	}
+	page.hist[0] = t
+	page.last = t
+	page.resident = true
+	lruk.resident.Set(page)
+
+	return nil
+}
+
+func (lruk *LRUK) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString(fmt.Sprintf("LRU-%d\n", lruk.k))
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", lruk.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", lruk.maxlen))
+	file.WriteString(fmt.Sprintf("correlated reference period: %d\n", lruk.crp))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", lruk.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", lruk.miss))
+	file.WriteString(fmt.Sprintf("correlated hit: %d\n", lruk.correlatedCount))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", lruk.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (lruk.hit / lruk.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(lruk.hit)/float64(lruk.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("history size : %d\n", lruk.history.Len()))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!LRU%d|%d|%d|%d\n", lruk.k, lruk.maxlen, lruk.hit, lruk.write))
+	return err
+}
+
+func (lruk *LRUK) Misses() int {
+	return lruk.miss
+}
+
+func (lruk *LRUK) Capacity() int {
+	return lruk.maxlen
+}
+
+func (lruk *LRUK) Metadata() simulator.Metadata {
+	// Page + riwayat K referensi + pointer di btree
+	pageBytes := int(unsafe.Sizeof(Page{})) + lruk.k*int(unsafe.Sizeof(int(0))) + int(unsafe.Sizeof(&Page{}))
+	return simulator.Metadata{
+		Tracked:       len(lruk.pages),
+		Ghost:         lruk.history.Len(),
+		BytesPerEntry: pageBytes + simulator.MapEntryBytes,
+	}
+}
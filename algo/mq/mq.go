@@ -0,0 +1,217 @@
+package mq
+
+import (
+	"container/list"
+	"fmt"
+	"math/bits"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+type (
+	Node struct {
+		lba        int
+		freq       int
+		queue      int
+		expireTime int
+	}
+
+	Ghost struct {
+		lba  int
+		freq int
+	}
+
+	// MQ mengikuti Multi-Queue (Zhou, Philbin, Li 2001): blok dengan
+	// frekuensi f masuk antrian LRU ke-log2(f), dan turun satu antrian
+	// jika tidak diakses selama lifeTime request.
+	MQ struct {
+		maxlen      int
+		queueCount  int
+		lifeTime    int
+		ghostSize   int
+		totalaccess int
+		hit         int
+		miss        int
+		pagefault   int
+		write       int
+		ghostHit    int
+		demotion    int
+
+		index  map[int]*list.Element
+		ghost  map[int]*list.Element
+		queues []*list.List
+		qout   *list.List
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "mq",
+		Description: "Multi-Queue",
+		Params: []simulator.Param{
+			{Name: "mq-queues", Kind: simulator.ParamInt, Default: 8, Usage: "jumlah antrian LRU pada MQ"},
+			{Name: "mq-lifetime", Kind: simulator.ParamInt, Default: 0, Usage: "lifetime blok (request) sebelum turun antrian (0 = ukuran cache)"},
+			{Name: "mq-ghost", Kind: simulator.ParamInt, Default: 0, Usage: "ukuran Qout (0 = 4x ukuran cache)"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			lifeTime, ghostSize := params.Int("mq-lifetime"), params.Int("mq-ghost")
+			if lifeTime == 0 {
+				lifeTime = cacheSize
+			}
+			if ghostSize == 0 {
+				ghostSize = 4 * cacheSize
+			}
+			return NewMQ(cacheSize, params.Int("mq-queues"), lifeTime, ghostSize)
+		},
+	})
+}
+
+func NewMQ(cacheSize, queueCount, lifeTime, ghostSize int) (*MQ, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("mq: cache size must be positive, got %d", cacheSize)
+	}
+	if queueCount < 1 {
+		return nil, fmt.Errorf("mq: queue count must be positive, got %d", queueCount)
+	}
+	if lifeTime < 0 || ghostSize < 0 {
+		return nil, fmt.Errorf("mq: lifetime and ghost size must not be negative")
+	}
+	mq := &MQ{
+		maxlen:     cacheSize,
+		queueCount: queueCount,
+		lifeTime:   lifeTime,
+		ghostSize:  ghostSize,
+		index:      make(map[int]*list.Element, cacheSize),
+		ghost:      make(map[int]*list.Element, ghostSize),
+		queues:     make([]*list.List, queueCount),
+		qout:       list.New(),
+	}
+	for i := range mq.queues {
+		mq.queues[i] = list.New()
+	}
+	return mq, nil
+}
+
+func (mq *MQ) queueNum(freq int) int {
+	k := bits.Len(uint(freq)) - 1
+	if k >= mq.queueCount {
+		k = mq.queueCount - 1
+	}
+	return k
+}
+
+func (mq *MQ) evict() {
+	for _, queue := range mq.queues {
+		if queue.Len() == 0 {
+			continue
+		}
+		victim := queue.Remove(queue.Front()).(*Node)
+		delete(mq.index, victim.lba)
+		if mq.ghostSize <= 0 {
+			return
+		}
+		if mq.qout.Len() >= mq.ghostSize {
+			old := mq.qout.Remove(mq.qout.Front()).(*Ghost)
+			delete(mq.ghost, old.lba)
+		}
+		mq.ghost[victim.lba] = mq.qout.PushBack(&Ghost{lba: victim.lba, freq: victim.freq})
+		return
+	}
+}
+
+// adjust menurunkan blok terdepan tiap antrian yang sudah kedaluwarsa
+func (mq *MQ) adjust() {
+	for k := 1; k < mq.queueCount; k++ {
+		front := mq.queues[k].Front()
+		if front == nil {
+			continue
+		}
+		node := front.Value.(*Node)
+		if node.expireTime < mq.totalaccess {
+			mq.demotion++
+			mq.queues[k].Remove(front)
+			node.queue = k - 1
+			node.expireTime = mq.totalaccess + mq.lifeTime
+			mq.index[node.lba] = mq.queues[k-1].PushBack(node)
+		}
+	}
+}
+
+func (mq *MQ) Get(trace simulator.Trace) (err error) {
+	mq.totalaccess++
+
+	var node *Node
+	if el, ok := mq.index[trace.Addr]; ok {
+		mq.hit++
+		if trace.Op == "W" {
+			mq.write++
+		}
+		node = el.Value.(*Node)
+		mq.queues[node.queue].Remove(el)
+		node.freq++
+	} else {
+		mq.miss++
+		mq.write++
+		node = &Node{lba: trace.Addr, freq: 1}
+		if el, ok := mq.ghost[trace.Addr]; ok {
+			mq.ghostHit++
+			node.freq = mq.qout.Remove(el).(*Ghost).freq + 1
+			delete(mq.ghost, trace.Addr)
+		}
+		if len(mq.index) >= mq.maxlen {
+			mq.pagefault++
+			mq.evict()
+		}
+	}
+
+	node.queue = mq.queueNum(node.freq)
+	node.expireTime = mq.totalaccess + mq.lifeTime
+	mq.index[node.lba] = mq.queues[node.queue].PushBack(node)
+	mq.adjust()
+
+	return nil
+}
+
+func (mq *MQ) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("MQ\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", mq.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", mq.maxlen))
+	file.WriteString(fmt.Sprintf("queues: %d\n", mq.queueCount))
+	file.WriteString(fmt.Sprintf("life time: %d\n", mq.lifeTime))
+	file.WriteString(fmt.Sprintf("qout size: %d\n", mq.ghostSize))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", mq.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", mq.miss))
+	file.WriteString(fmt.Sprintf("qout hit: %d\n", mq.ghostHit))
+	file.WriteString(fmt.Sprintf("demotion: %d\n", mq.demotion))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", mq.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (mq.hit / mq.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(mq.hit)/float64(mq.totalaccess))*100))
+	for k, queue := range mq.queues {
+		file.WriteString(fmt.Sprintf("queue %d size : %d\n", k, queue.Len()))
+	}
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!MQ|%d|%d|%d\n", mq.maxlen, mq.hit, mq.write))
+	return err
+}
+
+func (mq *MQ) Misses() int {
+	return mq.miss
+}
+
+func (mq *MQ) Capacity() int {
+	return mq.maxlen
+}
+
+func (mq *MQ) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(mq.index) + len(mq.ghost),
+		Ghost:         len(mq.ghost),
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + simulator.ListElementBytes + simulator.MapEntryBytes,
+	}
+}
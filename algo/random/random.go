@@ -0,0 +1,113 @@
+package random
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Random membuang blok acak; seed yang sama menghasilkan keputusan yang sama
+type Random struct {
+	maxlen      int
+	seed        int64
+	totalaccess int
+	hit         int
+	miss        int
+	pagefault   int
+	write       int
+
+	rand   *rand.Rand
+	blocks []int
+	index  map[int]int
+}
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "random",
+		Description: "Random replacement",
+		Params: []simulator.Param{
+			{Name: "random-seed", Kind: simulator.ParamInt64, Default: int64(1), Usage: "seed untuk algoritma RANDOM"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewRandom(cacheSize, params.Int64("random-seed"))
+		},
+	})
+}
+
+func NewRandom(cacheSize int, seed int64) (*Random, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("random: cache size must be positive, got %d", cacheSize)
+	}
+	return &Random{
+		maxlen: cacheSize,
+		seed:   seed,
+		rand:   rand.New(rand.NewSource(seed)),
+		blocks: make([]int, 0, cacheSize),
+		index:  make(map[int]int, cacheSize),
+	}, nil
+}
+
+func (random *Random) Get(trace simulator.Trace) (err error) {
+	random.totalaccess++
+
+	if _, ok := random.index[trace.Addr]; ok {
+		random.hit++
+		if trace.Op == "W" {
+			random.write++
+		}
+		return nil
+	}
+
+	random.miss++
+	random.write++
+	if len(random.blocks) < random.maxlen {
+		random.index[trace.Addr] = len(random.blocks)
+		random.blocks = append(random.blocks, trace.Addr)
+		return nil
+	}
+
+	random.pagefault++
+	slot := random.rand.Intn(len(random.blocks))
+	delete(random.index, random.blocks[slot])
+	random.blocks[slot] = trace.Addr
+	random.index[trace.Addr] = slot
+
+	return nil
+}
+
+func (random *Random) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("RANDOM\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", random.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", random.maxlen))
+	file.WriteString(fmt.Sprintf("seed: %d\n", random.seed))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", random.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", random.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", random.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (random.hit / random.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(random.hit)/float64(random.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!RANDOM|%d|%d|%d\n", random.maxlen, random.hit, random.write))
+	return err
+}
+
+func (random *Random) Misses() int {
+	return random.miss
+}
+
+func (random *Random) Capacity() int {
+	return random.maxlen
+}
+
+func (random *Random) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(random.index),
+		BytesPerEntry: int(unsafe.Sizeof(int(0))) + simulator.MapEntryBytes,
+	}
+}
@@ -0,0 +1,175 @@
+package s3fifo
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"ixtza/ajk/wec/algo/internal/queue"
+	"ixtza/ajk/wec/simulator"
+)
+
+const maxFreq = 3
+
+// S3FIFO mengikuti S3-FIFO (Yang dkk. 2023): FIFO kecil S untuk blok
+// baru, FIFO utama M, dan ghost FIFO G berisi blok yang keluar dari S.
+type S3FIFO struct {
+	maxlen      int
+	smallSize   int
+	totalaccess int
+	hit         int
+	miss        int
+	pagefault   int
+	write       int
+	ghostHit    int
+	promotion   int
+
+	small *queue.Queue[int]
+	main  *queue.Queue[int]
+	ghost *queue.Ghost
+}
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "s3fifo",
+		Description: "S3-FIFO",
+		Params: []simulator.Param{
+			{Name: "s3fifo-small", Kind: simulator.ParamFloat, Default: 10.0, Usage: "persentase FIFO kecil (S) terhadap cache"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewS3FIFO(cacheSize, params.Float("s3fifo-small"))
+		},
+	})
+}
+
+// NewS3FIFO membuat S3-FIFO dengan S sebesar smallPercentage persen cache
+func NewS3FIFO(cacheSize int, smallPercentage float64) (*S3FIFO, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("s3fifo: cache size must be positive, got %d", cacheSize)
+	}
+	if smallPercentage < 0 || smallPercentage > 100 {
+		return nil, fmt.Errorf("s3fifo: small queue percentage must be between 0 and 100, got %v", smallPercentage)
+	}
+	smallSize := int(float64(cacheSize) * smallPercentage / 100)
+	if smallSize < 1 {
+		smallSize = 1
+	}
+	ghostSize := cacheSize - smallSize
+	if ghostSize < 1 {
+		ghostSize = 1
+	}
+	return &S3FIFO{
+		maxlen:    cacheSize,
+		smallSize: smallSize,
+		small:     queue.New[int](),
+		main:      queue.New[int](),
+		ghost:     queue.NewGhost(ghostSize),
+	}, nil
+}
+
+func (s3 *S3FIFO) evict() {
+	for s3.small.Len()+s3.main.Len() >= s3.maxlen {
+		if s3.small.Len() >= s3.smallSize || s3.main.Len() == 0 {
+			s3.evictSmall()
+		} else {
+			s3.evictMain()
+		}
+	}
+}
+
+func (s3 *S3FIFO) evictSmall() {
+	tail := s3.small.PopBack()
+	key, freq := tail.Key, tail.Value
+	s3.small.Release(tail)
+	if freq > 1 {
+		// diakses lagi selama di S, pindah ke M
+		s3.promotion++
+		s3.main.PushFront(key, 0)
+		return
+	}
+	s3.ghost.Add(key)
+}
+
+func (s3 *S3FIFO) evictMain() {
+	for {
+		tail := s3.main.Back()
+		if tail.Value > 0 {
+			tail.Value--
+			s3.main.MoveToFront(tail)
+			continue
+		}
+		s3.main.Remove(tail)
+		s3.main.Release(tail)
+		return
+	}
+}
+
+func (s3 *S3FIFO) Get(trace simulator.Trace) (err error) {
+	s3.totalaccess++
+
+	e, ok := s3.small.Get(trace.Addr)
+	if !ok {
+		e, ok = s3.main.Get(trace.Addr)
+	}
+	if ok {
+		s3.hit++
+		if trace.Op == "W" {
+			s3.write++
+		}
+		if e.Value < maxFreq {
+			e.Value++
+		}
+		return nil
+	}
+
+	s3.miss++
+	s3.write++
+	if s3.small.Len()+s3.main.Len() >= s3.maxlen {
+		s3.pagefault++
+		s3.evict()
+	}
+	if s3.ghost.Remove(trace.Addr) {
+		s3.ghostHit++
+		s3.main.PushFront(trace.Addr, 0)
+		return nil
+	}
+	s3.small.PushFront(trace.Addr, 0)
+
+	return nil
+}
+
+func (s3 *S3FIFO) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("S3-FIFO\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", s3.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", s3.maxlen))
+	file.WriteString(fmt.Sprintf("small size: %d\n", s3.smallSize))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", s3.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", s3.miss))
+	file.WriteString(fmt.Sprintf("ghost hit: %d\n", s3.ghostHit))
+	file.WriteString(fmt.Sprintf("promotion: %d\n", s3.promotion))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", s3.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (s3.hit / s3.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(s3.hit)/float64(s3.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!S3FIFO|%d|%d|%d\n", s3.maxlen, s3.hit, s3.write))
+	return err
+}
+
+func (s3 *S3FIFO) Misses() int {
+	return s3.miss
+}
+
+func (s3 *S3FIFO) Capacity() int {
+	return s3.maxlen
+}
+
+func (s3 *S3FIFO) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       s3.small.Len() + s3.main.Len() + s3.ghost.Len(),
+		Ghost:         s3.ghost.Len(),
+		BytesPerEntry: s3.main.EntryBytes(),
+	}
+}
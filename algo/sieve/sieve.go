@@ -0,0 +1,125 @@
+package sieve
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+type (
+	Node struct {
+		lba     int
+		visited bool
+	}
+
+	SIEVE struct {
+		maxlen      int
+		totalaccess int
+		hit         int
+		miss        int
+		pagefault   int
+		write       int
+
+		// blok baru masuk di depan, jarum bergerak dari belakang ke depan
+		hand  *list.Element
+		queue *list.List
+		index map[int]*list.Element
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "sieve",
+		Description: "SIEVE",
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewSIEVE(cacheSize)
+		},
+	})
+}
+
+func NewSIEVE(cacheSize int) (*SIEVE, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("sieve: cache size must be positive, got %d", cacheSize)
+	}
+	return &SIEVE{
+		maxlen: cacheSize,
+		queue:  list.New(),
+		index:  make(map[int]*list.Element, cacheSize),
+	}, nil
+}
+
+func (sieve *SIEVE) evict() {
+	el := sieve.hand
+	if el == nil {
+		el = sieve.queue.Back()
+	}
+	for el.Value.(*Node).visited {
+		el.Value.(*Node).visited = false
+		el = el.Prev()
+		if el == nil {
+			el = sieve.queue.Back()
+		}
+	}
+	sieve.hand = el.Prev()
+	delete(sieve.index, el.Value.(*Node).lba)
+	sieve.queue.Remove(el)
+}
+
+func (sieve *SIEVE) Get(trace simulator.Trace) (err error) {
+	sieve.totalaccess++
+
+	if el, ok := sieve.index[trace.Addr]; ok {
+		sieve.hit++
+		if trace.Op == "W" {
+			sieve.write++
+		}
+		el.Value.(*Node).visited = true
+		return nil
+	}
+
+	sieve.miss++
+	sieve.write++
+	if sieve.queue.Len() >= sieve.maxlen {
+		sieve.pagefault++
+		sieve.evict()
+	}
+	sieve.index[trace.Addr] = sieve.queue.PushFront(&Node{lba: trace.Addr})
+
+	return nil
+}
+
+func (sieve *SIEVE) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("SIEVE\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", sieve.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", sieve.maxlen))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", sieve.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", sieve.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", sieve.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (sieve.hit / sieve.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(sieve.hit)/float64(sieve.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!SIEVE|%d|%d|%d\n", sieve.maxlen, sieve.hit, sieve.write))
+	return err
+}
+
+func (sieve *SIEVE) Misses() int {
+	return sieve.miss
+}
+
+func (sieve *SIEVE) Capacity() int {
+	return sieve.maxlen
+}
+
+func (sieve *SIEVE) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(sieve.index),
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + simulator.ListElementBytes + simulator.MapEntryBytes,
+	}
+}
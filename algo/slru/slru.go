@@ -0,0 +1,146 @@
+package slru
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+type (
+	Node struct {
+		lba       int
+		protected bool
+	}
+
+	SLRU struct {
+		maxlen        int
+		protectedSize int
+		totalaccess   int
+		hit           int
+		miss          int
+		pagefault     int
+		write         int
+		promotion     int
+
+		index     map[int]*list.Element
+		probation *list.List
+		protected *list.List
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "slru",
+		Description: "Segmented LRU",
+		Params: []simulator.Param{
+			{Name: "slru-protected", Kind: simulator.ParamFloat, Default: 80.0, Usage: "persentase segmen protected terhadap cache"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewSLRU(cacheSize, params.Float("slru-protected"))
+		},
+	})
+}
+
+// NewSLRU membagi cache menjadi segmen probation dan protected, dengan
+// protected sebesar protectedPercentage persen cache
+func NewSLRU(cacheSize int, protectedPercentage float64) (*SLRU, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("slru: cache size must be positive, got %d", cacheSize)
+	}
+	if protectedPercentage < 0 || protectedPercentage > 100 {
+		return nil, fmt.Errorf("slru: protected percentage must be between 0 and 100, got %v", protectedPercentage)
+	}
+	protectedSize := int(float64(cacheSize) * protectedPercentage / 100)
+	if protectedSize >= cacheSize {
+		protectedSize = cacheSize - 1
+	}
+	if protectedSize < 0 {
+		protectedSize = 0
+	}
+	return &SLRU{
+		maxlen:        cacheSize,
+		protectedSize: protectedSize,
+		index:         make(map[int]*list.Element, cacheSize),
+		probation:     list.New(),
+		protected:     list.New(),
+	}, nil
+}
+
+func (slru *SLRU) Get(trace simulator.Trace) (err error) {
+	slru.totalaccess++
+
+	if el, ok := slru.index[trace.Addr]; ok {
+		slru.hit++
+		if trace.Op == "W" {
+			slru.write++
+		}
+		node := el.Value.(*Node)
+		if node.protected {
+			slru.protected.MoveToFront(el)
+			return nil
+		}
+		slru.promotion++
+		slru.probation.Remove(el)
+		node.protected = true
+		slru.index[node.lba] = slru.protected.PushFront(node)
+		if slru.protected.Len() > slru.protectedSize {
+			demoted := slru.protected.Remove(slru.protected.Back()).(*Node)
+			demoted.protected = false
+			slru.index[demoted.lba] = slru.probation.PushFront(demoted)
+		}
+		return nil
+	}
+
+	slru.miss++
+	slru.write++
+	if slru.probation.Len()+slru.protected.Len() >= slru.maxlen {
+		slru.pagefault++
+		victimList := slru.probation
+		if victimList.Len() == 0 {
+			victimList = slru.protected
+		}
+		victim := victimList.Remove(victimList.Back()).(*Node)
+		delete(slru.index, victim.lba)
+	}
+	slru.index[trace.Addr] = slru.probation.PushFront(&Node{lba: trace.Addr})
+
+	return nil
+}
+
+func (slru *SLRU) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("SLRU\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", slru.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", slru.maxlen))
+	file.WriteString(fmt.Sprintf("protected size: %d\n", slru.protectedSize))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", slru.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", slru.miss))
+	file.WriteString(fmt.Sprintf("promotion: %d\n", slru.promotion))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", slru.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (slru.hit / slru.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(slru.hit)/float64(slru.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!SLRU|%d|%d|%d\n", slru.maxlen, slru.hit, slru.write))
+	return err
+}
+
+func (slru *SLRU) Misses() int {
+	return slru.miss
+}
+
+func (slru *SLRU) Capacity() int {
+	return slru.maxlen
+}
+
+func (slru *SLRU) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(slru.index),
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + simulator.ListElementBytes + simulator.MapEntryBytes,
+	}
+}
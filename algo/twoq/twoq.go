@@ -0,0 +1,174 @@
+package twoq
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+const (
+	a1in = iota
+	am
+)
+
+type (
+	Node struct {
+		lba   int
+		queue int
+	}
+
+	// TwoQ mengikuti 2Q versi lengkap (Johnson & Shasha 1994): A1in FIFO
+	// resident, A1out FIFO ghost, dan Am LRU resident.
+	TwoQ struct {
+		maxlen      int
+		kin         int
+		kout        int
+		totalaccess int
+		hit         int
+		miss        int
+		pagefault   int
+		write       int
+		ghostHit    int
+
+		index    map[int]*list.Element
+		ghost    map[int]*list.Element
+		a1inList *list.List
+		a1out    *list.List
+		amList   *list.List
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "2q",
+		Description: "2Q",
+		Params: []simulator.Param{
+			{Name: "twoq-kin", Kind: simulator.ParamFloat, Default: 25.0, Usage: "persentase A1in terhadap cache"},
+			{Name: "twoq-kout", Kind: simulator.ParamFloat, Default: 50.0, Usage: "persentase A1out (ghost) terhadap cache"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewTwoQ(cacheSize, params.Float("twoq-kin"), params.Float("twoq-kout"))
+		},
+	})
+}
+
+// NewTwoQ membuat 2Q dengan A1in sebesar kinPercentage persen cache dan
+// A1out mengingat koutPercentage persen cache
+func NewTwoQ(cacheSize int, kinPercentage, koutPercentage float64) (*TwoQ, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("2q: cache size must be positive, got %d", cacheSize)
+	}
+	if kinPercentage < 0 || kinPercentage > 100 {
+		return nil, fmt.Errorf("2q: A1in percentage must be between 0 and 100, got %v", kinPercentage)
+	}
+	if koutPercentage < 0 {
+		return nil, fmt.Errorf("2q: A1out percentage must not be negative, got %v", koutPercentage)
+	}
+	kin := int(float64(cacheSize) * kinPercentage / 100)
+	if kin < 1 {
+		kin = 1
+	}
+	kout := int(float64(cacheSize) * koutPercentage / 100)
+	if kout < 1 {
+		kout = 1
+	}
+	return &TwoQ{
+		maxlen:   cacheSize,
+		kin:      kin,
+		kout:     kout,
+		index:    make(map[int]*list.Element, cacheSize),
+		ghost:    make(map[int]*list.Element, kout),
+		a1inList: list.New(),
+		a1out:    list.New(),
+		amList:   list.New(),
+	}, nil
+}
+
+func (q *TwoQ) reclaim() {
+	if q.a1inList.Len()+q.amList.Len() < q.maxlen {
+		return
+	}
+	q.pagefault++
+	if q.a1inList.Len() > q.kin || q.amList.Len() == 0 {
+		node := q.a1inList.Remove(q.a1inList.Back()).(*Node)
+		delete(q.index, node.lba)
+		q.ghost[node.lba] = q.a1out.PushFront(node.lba)
+		if q.a1out.Len() > q.kout {
+			delete(q.ghost, q.a1out.Remove(q.a1out.Back()).(int))
+		}
+		return
+	}
+	node := q.amList.Remove(q.amList.Back()).(*Node)
+	delete(q.index, node.lba)
+}
+
+func (q *TwoQ) Get(trace simulator.Trace) (err error) {
+	q.totalaccess++
+
+	if el, ok := q.index[trace.Addr]; ok {
+		q.hit++
+		if trace.Op == "W" {
+			q.write++
+		}
+		if el.Value.(*Node).queue == am {
+			q.amList.MoveToFront(el)
+		}
+		return nil
+	}
+
+	q.miss++
+	q.write++
+	q.reclaim()
+
+	if el, ok := q.ghost[trace.Addr]; ok {
+		// pernah keluar dari A1in, berarti cukup sering dipakai
+		q.ghostHit++
+		q.a1out.Remove(el)
+		delete(q.ghost, trace.Addr)
+		q.index[trace.Addr] = q.amList.PushFront(&Node{lba: trace.Addr, queue: am})
+		return nil
+	}
+	q.index[trace.Addr] = q.a1inList.PushFront(&Node{lba: trace.Addr, queue: a1in})
+
+	return nil
+}
+
+func (q *TwoQ) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("2Q\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", q.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", q.maxlen))
+	file.WriteString(fmt.Sprintf("kin: %d\n", q.kin))
+	file.WriteString(fmt.Sprintf("kout: %d\n", q.kout))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", q.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", q.miss))
+	file.WriteString(fmt.Sprintf("a1out hit: %d\n", q.ghostHit))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", q.write))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (q.hit / q.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(q.hit)/float64(q.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!2Q|%d|%d|%d\n", q.maxlen, q.hit, q.write))
+	return err
+}
+
+func (q *TwoQ) Misses() int {
+	return q.miss
+}
+
+func (q *TwoQ) Capacity() int {
+	return q.maxlen
+}
+
+func (q *TwoQ) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(q.index) + len(q.ghost),
+		Ghost:         len(q.ghost),
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + simulator.ListElementBytes + simulator.MapEntryBytes,
+	}
+}
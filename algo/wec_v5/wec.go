@@ -1,25 +1,66 @@
 package wec_v5
 
 import (
+	"encoding/gob"
 	"fmt"
+	"ixtza/ajk/wec/algo/internal/orderedmap"
 	"ixtza/ajk/wec/simulator"
 	"math"
 	"os"
+	"sort"
 	"strings"
 	"time"
+	"unsafe"
 
-	"github.com/secnot/orderedmap"
 	"github.com/tidwall/btree"
 )
 
+// Location adalah tier tempat data blok berada
+type Location uint8
+
+const (
+	LocationRAM Location = iota + 1
+	LocationSSD
+	LocationHDD
+)
+
+func (location Location) String() string {
+	switch location {
+	case LocationRAM:
+		return "RAM"
+	case LocationSSD:
+		return "SSD"
+	case LocationHDD:
+		return "HDD"
+	}
+	return "UNKNOWN"
+}
+
+func (location Location) tier() simulator.Tier {
+	switch location {
+	case LocationRAM:
+		return simulator.TierRAM
+	case LocationSSD:
+		return simulator.TierSSD
+	case LocationHDD:
+		return simulator.TierHDD
+	}
+	return simulator.TierNone
+}
+
 type (
 	WECData struct {
 		address  int
-		location string
+		location Location
 
 		accessCount int
 		lastAccess  int
-		idleTime    int
+		// epoch eviksi saat data melewati quitThreshold di SPQueue
+		quitEpoch int
+		// request saat masuk SPQueue dan epoch saat idleTime bernilai 0,
+		// untuk menghitung lama tinggal di SPQueue
+		spqRequest   int
+		spqIdleEpoch int
 
 		// spq bool
 	}
@@ -50,25 +91,133 @@ type (
 
 		updatePeriode int
 
-		WCQueue  *orderedmap.OrderedMap
-		SPQueue  *orderedmap.OrderedMap
-		RAMQueue *orderedmap.OrderedMap
+		// jumlah entri WCQueue yang datanya hanya ada di HDD (ghost)
+		wcqGhostCount int
+
+		// evictionEpoch bertambah setiap wcqEvict, menggantikan penambahan
+		// idleTime pada seluruh SPQueue; spqExpiry mengelompokkan data SPQueue
+		// berdasarkan epoch keluarnya
+		evictionEpoch int
+		spqExpiry     map[int][]*WECData
+
+		// lama tinggal di SPQueue per cara keluar (spqReread, spqWrite,
+		// spqQuit) dalam request dan idleTime
+		spqDwellRequests [spqOutcomes]simulator.Histogram
+		spqDwellIdle     [spqOutcomes]simulator.Histogram
+
+		// data RAM yang sudah ditulis (hit W di RAM) dan akan hilang saat
+		// crash bila cache memakai write-back
+		dirty map[*WECData]struct{}
+
+		WCQueue  *orderedmap.OrderedMap[*WECData]
+		SPQueue  *orderedmap.OrderedMap[*WECData]
+		RAMQueue *orderedmap.OrderedMap[*WECData]
 
 		SSDMap  map[int]*WECData
-		WCQTree *btree.Map[int, *orderedmap.OrderedMap]
+		WCQTree *btree.Map[int, *orderedmap.OrderedMap[*WECData]]
+
+		simulator.Emitter
 	}
 )
 
-var peek any
+// cara data keluar dari SPQueue
+const (
+	spqReread = iota
+	spqWrite
+	spqQuit
+	spqOutcomes
+)
+
+var spqOutcomeNames = [spqOutcomes]string{"reread", "write", "quit"}
 
-func catch() {
+// catch mengubah panic di dalam Get menjadi error; isi cache tidak lagi
+// konsisten setelahnya sehingga simulasi sebaiknya dihentikan
+func (wec *WECache) catch(err *error) {
 	if r := recover(); r != nil {
-		fmt.Println(peek)
-		fmt.Println(r)
-		panic("WEC Overflow")
+		*err = fmt.Errorf("wec: overflow at request %d: %v", wec.requestCount, r)
+	}
+}
+
+// quitThresholdTypes adalah tipe quit threshold yang dikenali, "" berarti
+// linear. Ejaan dengan tanda hubung (cube-root, square-root) dulu diam-diam
+// dihitung sebagai linear sehingga ditolak agar hasil lama tidak berubah
+// tanpa disadari.
+var quitThresholdTypes = map[string]string{
+	"":            "linear",
+	"linear":      "linear",
+	"cubic":       "cubic",
+	"quadratic":   "quadratic",
+	"square_root": "square_root",
+	"cube_root":   "cube_root",
+}
+
+var hyphenatedQuitThresholdTypes = map[string]string{
+	"square-root": "square_root",
+	"cube-root":   "cube_root",
+}
+
+func validate(
+	capacitySize int,
+	updatingPeriod int,
+	quitThresholdType string,
+	ramPercentage float32,
+	capacitySizeRatio float32,
+	wecDataThreshold float32,
+) error {
+	if capacitySize <= 0 {
+		return fmt.Errorf("wec: capacity size must be positive, got %d", capacitySize)
+	}
+	if updatingPeriod <= 0 {
+		return fmt.Errorf("wec: update periode must be positive, got %d", updatingPeriod)
 	}
+	if canonical, ok := hyphenatedQuitThresholdTypes[quitThresholdType]; ok {
+		return fmt.Errorf("wec: quit threshold type %q is not accepted (earlier versions treated it as linear); use %s, or linear to reproduce old results", quitThresholdType, canonical)
+	}
+	if _, ok := quitThresholdTypes[quitThresholdType]; !ok {
+		return fmt.Errorf("wec: unknown quit threshold type %q (cube_root|square_root|cubic|quadratic|linear)", quitThresholdType)
+	}
+	if ramPercentage < 0 || ramPercentage > 1 {
+		return fmt.Errorf("wec: ram percentage must be between 0 and 1, got %v", ramPercentage)
+	}
+	if capacitySizeRatio <= 0 || capacitySizeRatio > 1 {
+		return fmt.Errorf("wec: capacity ratio must be in (0, 1], got %v", capacitySizeRatio)
+	}
+	if int(float32(capacitySize)*capacitySizeRatio) < 1 {
+		return fmt.Errorf("wec: capacity ratio %v leaves no cache for capacity %d", capacitySizeRatio, capacitySize)
+	}
+	if wecDataThreshold < 0 || wecDataThreshold > 1 {
+		return fmt.Errorf("wec: threshold must be between 0 and 1, got %v", wecDataThreshold)
+	}
+	return nil
 }
 
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "wecv5",
+		Description: "Write-Efficient Cache (RAM + SSD di atas HDD)",
+		Params: []simulator.Param{
+			{Name: "wec-update-periode", Kind: simulator.ParamInt, Default: 1000, Usage: "periode pembaruan cache"},
+			{Name: "wec-qt-type", Kind: simulator.ParamString, Default: "linear", Usage: "tipe konfigurasi batas umur cache\n(cube_root|square_root|cubic|quadratic|linear)"},
+			{Name: "wec-ram-percentage", Kind: simulator.ParamFloat, Default: 0.1, Usage: "rasio ram terhadap cache"},
+			{Name: "wec-capacity-ratio", Kind: simulator.ParamFloat, Default: 1.0, Usage: "rasio cache terhadap memori"},
+			{Name: "wec-threshold", Kind: simulator.ParamFloat, Default: 0.5, Usage: "batas rasio pengambilan kandidat cache"},
+		},
+		OutputTags: []string{"wec-capacity-ratio", "wec-ram-percentage", "wec-qt-type", "wec-update-periode"},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return New(
+				cacheSize,
+				params.Int("wec-update-periode"),
+				params.String("wec-qt-type"),
+				float32(params.Float("wec-ram-percentage")),
+				float32(params.Float("wec-capacity-ratio")),
+				float32(params.Float("wec-threshold")),
+			)
+		},
+	})
+}
+
+// New memvalidasi parameter sebelum membuat WEC; capacitySize adalah
+// ukuran HDD dan cache sebesar capacitySizeRatio darinya
 func New(
 	capacitySize int,
 	updatingPeriod int,
@@ -76,7 +225,11 @@ func New(
 	ramPercentage float32,
 	capacitySizeRatio float32,
 	wecDataThreshold float32,
-) simulator.Simulator {
+) (*WECache, error) {
+	if err := validate(capacitySize, updatingPeriod, quitThresholdType, ramPercentage, capacitySizeRatio, wecDataThreshold); err != nil {
+		return nil, err
+	}
+	quitThresholdType = quitThresholdTypes[quitThresholdType]
 
 	var cacheSize int
 
@@ -101,11 +254,11 @@ func New(
 
 	var updatePeriode int
 
-	var WCQueue *orderedmap.OrderedMap
-	var SPQueue *orderedmap.OrderedMap
-	var RAMQueue *orderedmap.OrderedMap
+	var WCQueue *orderedmap.OrderedMap[*WECData]
+	var SPQueue *orderedmap.OrderedMap[*WECData]
+	var RAMQueue *orderedmap.OrderedMap[*WECData]
 	var SSDMap map[int]*WECData
-	var WCQTree *btree.Map[int, *orderedmap.OrderedMap]
+	var WCQTree *btree.Map[int, *orderedmap.OrderedMap[*WECData]]
 
 	hitCount = 0
 	missCount = 0
@@ -126,12 +279,12 @@ func New(
 
 	wcqSize = cacheSize + int(float32(cacheSize)*0.1)
 
-	WCQueue = orderedmap.NewOrderedMap()
-	SPQueue = orderedmap.NewOrderedMap()
-	RAMQueue = orderedmap.NewOrderedMap()
+	WCQueue = orderedmap.NewPooled[*WECData]()
+	SPQueue = orderedmap.NewPooled[*WECData]()
+	RAMQueue = orderedmap.NewPooled[*WECData]()
 
 	SSDMap = map[int]*WECData{}
-	WCQTree = btree.NewMap[int, *orderedmap.OrderedMap](2)
+	WCQTree = btree.NewMap[int, *orderedmap.OrderedMap[*WECData]](2)
 
 	return &WECache{
 		ramSize:         ramSize,
@@ -163,7 +316,10 @@ func New(
 		RAMQueue: RAMQueue,
 		SSDMap:   SSDMap,
 		WCQTree:  WCQTree,
-	}
+
+		spqExpiry: map[int][]*WECData{},
+		dirty:     map[*WECData]struct{}{},
+	}, nil
 }
 
 func calculateQuitThreshold(
@@ -186,19 +342,28 @@ func calculateQuitThreshold(
 }
 
 func (wec *WECache) ramReplace() (err error) {
-	address, wecData, ok := wec.RAMQueue.GetFirst()
+	address, data, ok := wec.RAMQueue.GetFirst()
 	if ok {
-		data := wecData.(*WECData)
 		if wec.RAMQueue.Len() > wec.ramSize {
-			data.setLocation("HDD")
+			wec.relocate(data, LocationHDD)
 			wec.RAMQueue.Delete(address)
 		}
 	}
 	return
 }
+// ramSet menimpa data lama alamat yang sama di RAMQueue; status dirty
+// ikut berpindah karena isi RAM blok tersebut tetap sama
+func (wec *WECache) ramSet(address int, data *WECData) {
+	if old, ok := wec.RAMQueue.Get(address); ok && old != data {
+		if _, dirty := wec.dirty[old]; dirty {
+			delete(wec.dirty, old)
+			wec.dirty[data] = struct{}{}
+		}
+	}
+	wec.RAMQueue.Set(address, data)
+}
 func (wec *WECache) ramGetData(address int) (wecData *WECData) {
-	data, _ := wec.RAMQueue.Get(address)
-	wecData, _ = data.(*WECData)
+	wecData, _ = wec.RAMQueue.Get(address)
 	return
 }
 
@@ -208,7 +373,7 @@ func (wec *WECache) wcqTreeUpsertData(accessCount, address int, data *WECData) (
 	}
 	wcqTreeData, wcqTreeDataExists := wec.WCQTree.Get(data.accessCount)
 	if !wcqTreeDataExists {
-		newMap := orderedmap.NewOrderedMap()
+		newMap := orderedmap.NewPooled[*WECData]()
 		newMap.Set(address, data)
 		wec.WCQTree.Set(accessCount, newMap)
 		wec.candidateCount += 1
@@ -247,10 +412,9 @@ func (wec *WECache) wcqTreeRemoveData(accessCount, address int) (err error) {
 	return
 }
 func (wec *WECache) wcqTreeFetchCandidate(weCandidateCount int) (datas []*WECData) {
-	wec.WCQTree.Reverse(func(key int, value *orderedmap.OrderedMap) bool {
+	wec.WCQTree.Reverse(func(key int, value *orderedmap.OrderedMap[*WECData]) bool {
 		iter := value.IterReverse()
-		for _, wecData, ok := iter.Next(); ok; _, wecData, ok = iter.Next() {
-			data, _ := wecData.(*WECData)
+		for _, data, ok := iter.Next(); ok; _, data, ok = iter.Next() {
 			if weCandidateCount == len(datas) {
 				return false
 			}
@@ -261,19 +425,18 @@ func (wec *WECache) wcqTreeFetchCandidate(weCandidateCount int) (datas []*WECDat
 	return
 }
 func (wec *WECache) wcqEvict() (err error) {
-	address, wecData, ok := wec.WCQueue.GetFirst()
+	address, data, ok := wec.WCQueue.GetFirst()
 	if ok {
-		data := wecData.(*WECData)
-		if data.location == "SSD" && wec.wcqSize < wec.WCQueue.Len() {
+		if data.location == LocationSSD && wec.wcqSize < wec.WCQueue.Len() {
 			wec.spqAddBlock(data.address, data)
 			wec.WCQueue.Delete(address)
-		} else if data.location == "HDD" && wec.wcqSize < wec.WCQueue.Len() {
+		} else if data.location == LocationHDD && wec.wcqSize < wec.WCQueue.Len() {
 			wec.wcqRemoveBlock(data)
-		} else if data.location == "RAM" && wec.wcqSize < wec.WCQueue.Len() {
+		} else if data.location == LocationRAM && wec.wcqSize < wec.WCQueue.Len() {
 			wec.ramReplace()
 			wec.wcqRemoveBlock(data)
 		}
-		wec.spqIncreaseIdleTime()
+		wec.spqAdvanceEpoch()
 	}
 	return
 }
@@ -282,28 +445,30 @@ func (wec *WECache) wcqAddBlock(address int) (err error) {
 		address:     address,
 		accessCount: 1,
 		lastAccess:  wec.requestCount,
-		location:    "RAM",
-		idleTime:    0,
+		location:    LocationRAM,
 		// spq:         false,
 	}
 	wec.WCQueue.Set(address, newBlock)
-	wec.RAMQueue.Set(address, newBlock)
+	wec.ramSet(address, newBlock)
+	wec.emit(simulator.EventInsert, address, simulator.TierRAM)
 	wec.wcqTreeUpsertData(newBlock.accessCount, address, newBlock)
 	return
 }
 func (wec *WECache) wcqRemoveBlock(data *WECData) (err error) {
+	if data.location == LocationHDD && wec.wcqHolds(data) {
+		wec.wcqGhostCount -= 1
+	}
 	wec.wcqTreeRemoveData(data.accessCount, data.address)
 	wec.WCQueue.Delete(data.address)
 	return
 }
 func (wec *WECache) wcqGetData(address int) (wecData *WECData) {
-	data, _ := wec.WCQueue.Get(address)
-	wecData, _ = data.(*WECData)
+	wecData, _ = wec.WCQueue.Get(address)
 	return
 }
 func (wec *WECache) wcqRequestReadHDD(address int, wecData *WECData) (err error) {
-	wecData.setLocation("RAM")
-	wec.RAMQueue.Set(address, wecData)
+	wec.relocate(wecData, LocationRAM)
+	wec.ramSet(address, wecData)
 	wec.RAMQueue.MoveLast(address)
 	return
 }
@@ -312,33 +477,58 @@ func (wec *WECache) wcqRequestReadRAM(address int) (err error) {
 	return
 }
 
-func (wec *WECache) spqIncreaseIdleTime() (err error) {
-	iter := wec.SPQueue.Iter()
-	for _, wecData, ok := iter.Next(); ok; _, wecData, ok = iter.Next() {
-		data := wecData.(*WECData)
-		data.idleTime += 1
-		if data.idleTime > wec.quitThreshold {
-			delete(wec.SSDMap, data.address)
-			wec.SPQueue.Delete(data.address)
+// spqAdvanceEpoch setara dengan menambah idleTime seluruh SPQueue lalu
+// mengeluarkan data dengan idleTime > quitThreshold, tetapi hanya menyentuh
+// data yang epoch keluarnya jatuh pada epoch ini
+func (wec *WECache) spqAdvanceEpoch() (err error) {
+	wec.evictionEpoch += 1
+	expired := wec.spqExpiry[wec.evictionEpoch]
+	delete(wec.spqExpiry, wec.evictionEpoch)
+	for _, data := range expired {
+		// data yang sudah keluar/masuk lagi ke SPQueue memiliki epoch lain
+		if data.quitEpoch != wec.evictionEpoch || wec.spqGetData(data.address) != data {
+			continue
 		}
+		delete(wec.SSDMap, data.address)
+		wec.SPQueue.Delete(data.address)
+		wec.spqLeave(data, spqQuit)
+		// evict hanya dilaporkan untuk data yang masih di SSD; data yang
+		// sudah di HDD dilaporkan saat dipindah, dan lokasi HDD mencegah
+		// ramReplace melaporkannya lagi lewat entri RAMQueue lama
+		wec.relocate(data, LocationHDD)
 	}
 	return
 }
 func (wec *WECache) spqAddBlock(address int, wecData *WECData) (err error) {
-	wecData.idleTime = wec.WCQueue.Len() - 1
+	// idleTime awal sebesar panjang WCQueue, data keluar setelah idleTime
+	// melewati quitThreshold dan paling cepat pada epoch berikutnya
+	idleTime := wec.WCQueue.Len() - 1
+	remaining := wec.quitThreshold + 1 - idleTime
+	if remaining < 1 {
+		remaining = 1
+	}
+	wecData.quitEpoch = wec.evictionEpoch + remaining
+	wecData.spqRequest = wec.requestCount
+	wecData.spqIdleEpoch = wec.evictionEpoch - idleTime
+	wec.spqExpiry[wecData.quitEpoch] = append(wec.spqExpiry[wecData.quitEpoch], wecData)
 	wec.SPQueue.Set(address, wecData)
 	return
 }
+
+// spqLeave mencatat lama tinggal data yang keluar dari SPQueue
+func (wec *WECache) spqLeave(wecData *WECData, outcome int) {
+	wec.spqDwellRequests[outcome].Add(int64(wec.requestCount - wecData.spqRequest))
+	wec.spqDwellIdle[outcome].Add(int64(wec.evictionEpoch - wecData.spqIdleEpoch))
+}
 func (wec *WECache) spqGetData(address int) (wecData *WECData) {
-	data, _ := wec.SPQueue.Get(address)
-	wecData, _ = data.(*WECData)
+	wecData, _ = wec.SPQueue.Get(address)
 	return
 }
 func (wec *WECache) spqRequestRead(address int, wecData *WECData) (err error) {
-	wecData.idleTime = 0
 	// wecData.spq = false
 	wec.ssdHitCount += 1
 	wec.SPQueue.Delete(address)
+	wec.spqLeave(wecData, spqReread)
 	return
 }
 
@@ -384,15 +574,14 @@ func (wec *WECache) ssdUpdate() (err error) {
 		// ITER WCQ DARI BELAKANG
 		wec.wcqSize = newWCQSize
 		for deleteCount != 0 {
-			address, wecData, ok := wec.WCQueue.GetFirst()
+			address, data, ok := wec.WCQueue.GetFirst()
 			if ok {
-				data := wecData.(*WECData)
-				if data.location == "SSD" && wec.wcqSize < wec.WCQueue.Len() {
+				if data.location == LocationSSD && wec.wcqSize < wec.WCQueue.Len() {
 					wec.spqAddBlock(data.address, data)
 					wec.WCQueue.Delete(address)
-				} else if data.location == "HDD" && wec.wcqSize < wec.WCQueue.Len() {
+				} else if data.location == LocationHDD && wec.wcqSize < wec.WCQueue.Len() {
 					wec.wcqRemoveBlock(data)
-				} else if data.location == "RAM" && wec.wcqSize < wec.WCQueue.Len() {
+				} else if data.location == LocationRAM && wec.wcqSize < wec.WCQueue.Len() {
 					wec.wcqRemoveBlock(data)
 				}
 				deleteCount -= 1
@@ -405,20 +594,24 @@ func (wec *WECache) ssdUpdate() (err error) {
 	return
 }
 func (wec *WECache) ssdAddBlock(wecData *WECData) (err error) {
-	wecData.setLocation("SSD")
+	wec.relocate(wecData, LocationSSD)
 	wec.writeCount += 1
+	wec.emit(simulator.EventSSDWrite, wecData.address, simulator.TierSSD)
 	wec.SSDMap[wecData.address] = wecData
 	return
 }
 
 func (wec *WECache) Get(trace simulator.Trace) (err error) {
 
-	defer catch()
+	defer wec.catch(&err)
 
 	wec.requestCount += 1
 
 	address := trace.Addr
 	request := strings.ToUpper(trace.Op)
+	if request != "R" && request != "W" {
+		return fmt.Errorf("wec: unknown op %q at request %d", trace.Op, wec.requestCount)
+	}
 
 	if wec.requestCount%wec.updatePeriode == 0 {
 		res := wec.ssdUpdate()
@@ -435,21 +628,24 @@ func (wec *WECache) Get(trace simulator.Trace) (err error) {
 		if wcqData != nil {
 			wcqData.accessCount += 1
 			wec.WCQueue.MoveLast(address)
-			if wcqData.location == "RAM" {
+			if wcqData.location == LocationRAM {
 				// HANDLE WCQ READ RAM
 				wec.wcqTreeUpsertData(wcqData.accessCount, wcqData.address, wcqData)
 				wec.wcqRequestReadRAM(address)
 				wec.hitCount += 1
-			} else if wcqData.location == "HDD" {
+				wec.emit(simulator.EventHit, address, simulator.TierRAM)
+			} else if wcqData.location == LocationHDD {
 				// HANDLE WCQ READ HDD
 				wec.wcqTreeUpsertData(wcqData.accessCount, wcqData.address, wcqData)
 				wec.missCount += 1
+				wec.emit(simulator.EventMiss, address, simulator.TierHDD)
 				wec.wcqRequestReadHDD(address, wcqData)
 				wec.ramReplace()
-			} else if wcqData.location == "SSD" {
+			} else if wcqData.location == LocationSSD {
 				// HANDLE WCQ READ SSD
 				wec.hitCount += 1
 				wec.ssdHitCount += 1
+				wec.emit(simulator.EventHit, address, simulator.TierSSD)
 			}
 			return
 		}
@@ -458,9 +654,13 @@ func (wec *WECache) Get(trace simulator.Trace) (err error) {
 		if spqData != nil {
 			spqData.accessCount += 1
 			wec.hitCount += 1
+			wec.emit(simulator.EventHit, address, spqData.location.tier())
 			// HANDLE SPQ READ SSD
 			wec.spqRequestRead(address, spqData)
 			wec.WCQueue.Set(address, spqData)
+			if spqData.location == LocationHDD {
+				wec.wcqGhostCount += 1
+			}
 			wec.wcqEvict()
 			return
 		}
@@ -470,6 +670,7 @@ func (wec *WECache) Get(trace simulator.Trace) (err error) {
 			wec.ramHitCount += 1
 		}
 		wec.missCount += 1
+		wec.emit(simulator.EventMiss, address, simulator.TierHDD)
 		wec.wcqAddBlock(address)
 		wec.ramReplace()
 		wec.wcqEvict()
@@ -479,15 +680,19 @@ func (wec *WECache) Get(trace simulator.Trace) (err error) {
 		wec.writeRequestCount += 1
 		wcqData := wec.wcqGetData(address)
 		if wcqData != nil {
-			if wcqData.location == "RAM" {
+			if wcqData.location == LocationRAM {
 				wec.hitCount += 1
+				wec.emit(simulator.EventHit, address, simulator.TierRAM)
+				wec.dirty[wcqData] = struct{}{}
 				wec.wcqRemoveBlock(wcqData)
-			} else if wcqData.location == "SSD" {
+			} else if wcqData.location == LocationSSD {
 				wec.hitCount += 1
 				wec.ssdHitCount += 1
+				wec.emit(simulator.EventHit, address, simulator.TierSSD)
 				wec.WCQueue.Delete(address)
-			} else if wcqData.location == "HDD" {
+			} else if wcqData.location == LocationHDD {
 				wec.missCount += 1
+				wec.emit(simulator.EventMiss, address, simulator.TierHDD)
 				wec.wcqRemoveBlock(wcqData)
 			}
 			return
@@ -497,17 +702,107 @@ func (wec *WECache) Get(trace simulator.Trace) (err error) {
 		if spqData != nil {
 			wec.hitCount += 1
 			wec.ssdHitCount += 1
+			wec.emit(simulator.EventHit, address, spqData.location.tier())
 			wec.SPQueue.Delete(address)
+			wec.spqLeave(spqData, spqWrite)
 			return
 		}
 		wec.missCount += 1
+		wec.emit(simulator.EventMiss, address, simulator.TierHDD)
 		return
 	}
 
 	return
 }
 
-func (wec *WECData) setLocation(location string) (err error) {
+// relocate memindahkan data ke lokasi baru sambil menjaga wcqGhostCount;
+// ke HDD dilaporkan sebagai evict, dari HDD sebagai insert
+func (wec *WECache) relocate(data *WECData, location Location) {
+	if wec.wcqHolds(data) {
+		if data.location == LocationHDD && location != LocationHDD {
+			wec.wcqGhostCount -= 1
+		} else if data.location != LocationHDD && location == LocationHDD {
+			wec.wcqGhostCount += 1
+		}
+	}
+	if data.location == LocationRAM && location != LocationRAM {
+		delete(wec.dirty, data)
+	}
+	switch {
+	case data.location == location:
+	case location == LocationHDD:
+		wec.emit(simulator.EventEvict, data.address, data.location.tier())
+	case data.location == LocationHDD:
+		wec.emit(simulator.EventInsert, data.address, location.tier())
+	case location == LocationRAM:
+		wec.EmitMove(simulator.EventPromote, data.address, data.location.tier(), location.tier(), wec.requestCount)
+	default:
+		wec.EmitMove(simulator.EventDemote, data.address, data.location.tier(), location.tier(), wec.requestCount)
+	}
+	data.setLocation(location)
+}
+
+// Crash mengosongkan RAMQueue; data yang masih di RAM pindah ke HDD dan
+// yang dirty dihitung hilang. WCQueue, SPQueue dan SSDMap dianggap
+// persisten bersama SSD.
+func (wec *WECache) Crash() (result simulator.CrashResult) {
+	iter := wec.RAMQueue.Iter()
+	for _, data, ok := iter.Next(); ok; _, data, ok = iter.Next() {
+		if data.location != LocationRAM {
+			continue
+		}
+		result.Cleared++
+		if _, ok := wec.dirty[data]; ok {
+			result.Dirty++
+		}
+		wec.relocate(data, LocationHDD)
+	}
+	wec.RAMQueue = orderedmap.NewPooled[*WECData]()
+	clear(wec.dirty)
+	return result
+}
+
+func (wec *WECache) emit(kind simulator.EventKind, address int, tier simulator.Tier) {
+	wec.Emit(kind, address, tier, wec.requestCount)
+}
+
+func (wec *WECache) wcqHolds(data *WECData) bool {
+	wcqData, ok := wec.WCQueue.Get(data.address)
+	return ok && wcqData == data
+}
+
+// QueueDwell melaporkan lama tinggal di SPQueue; umur adalah idleTime yang
+// dibandingkan dengan quitThreshold
+func (wec *WECache) QueueDwell() simulator.QueueDwell {
+	dwell := simulator.QueueDwell{Queue: "SPQueue", Limit: wec.quitThreshold, LimitType: wec.quitThresholdType}
+	for outcome, name := range spqOutcomeNames {
+		dwell.Outcomes = append(dwell.Outcomes, simulator.DwellOutcome{
+			Name:     name,
+			Requests: wec.spqDwellRequests[outcome],
+			Age:      wec.spqDwellIdle[outcome],
+		})
+	}
+	return dwell
+}
+
+func (wec *WECache) Misses() int {
+	return wec.missCount
+}
+
+func (wec *WECache) Capacity() int {
+	return wec.ramSize + wec.ssdSize
+}
+
+func (wec *WECache) Metadata() simulator.Metadata {
+	// WECData dirujuk dari WCQueue/SPQueue dan WCQTree
+	return simulator.Metadata{
+		Tracked:       wec.WCQueue.Len() + wec.SPQueue.Len(),
+		Ghost:         wec.wcqGhostCount,
+		BytesPerEntry: int(unsafe.Sizeof(WECData{})) + 2*wec.WCQueue.EntryBytes(),
+	}
+}
+
+func (wec *WECData) setLocation(location Location) (err error) {
 	wec.location = location
 	return nil
 }
@@ -560,3 +855,213 @@ duration:%v
 	_, err = file.WriteString(result)
 	return
 }
+
+// wecState adalah isi snapshot WEC. Satu alamat bisa memiliki beberapa
+// WECData (misalnya di SSDMap dan WCQueue) dan identitasnya dipakai saat
+// membandingkan, sehingga setiap struktur menyimpan indeks ke Data
+type wecState struct {
+	WCQSize           int
+	CandidateCount    int
+	RequestCount      int
+	HitCount          int
+	MissCount         int
+	WriteCount        int
+	ReadRequestCount  int
+	WriteRequestCount int
+	SSDHitCount       int
+	RAMHitCount       int
+	WCQGhostCount     int
+	EvictionEpoch     int
+
+	Data      []wecDataState
+	WCQueue   []int
+	SPQueue   []int
+	RAMQueue  []int
+	SSDMap    []int
+	Dirty     []int
+	WCQTree   []wecGroupState
+	SPQExpiry []wecGroupState
+
+	SPQDwellRequests [spqOutcomes]simulator.Histogram
+	SPQDwellIdle     [spqOutcomes]simulator.Histogram
+}
+
+type wecDataState struct {
+	Address      int
+	Location     Location
+	AccessCount  int
+	LastAccess   int
+	QuitEpoch    int
+	SPQRequest   int
+	SPQIdleEpoch int
+}
+
+// wecGroupState adalah satu bucket WCQTree (Key = accessCount) atau satu
+// epoch spqExpiry
+type wecGroupState struct {
+	Key   int
+	Items []int
+}
+
+func (wec *WECache) Snapshot(enc *gob.Encoder) error {
+	state := wecState{
+		WCQSize:           wec.wcqSize,
+		CandidateCount:    wec.candidateCount,
+		RequestCount:      wec.requestCount,
+		HitCount:          wec.hitCount,
+		MissCount:         wec.missCount,
+		WriteCount:        wec.writeCount,
+		ReadRequestCount:  wec.readRequestCount,
+		WriteRequestCount: wec.writeRequestCount,
+		SSDHitCount:       wec.ssdHitCount,
+		RAMHitCount:       wec.ramHitCount,
+		WCQGhostCount:     wec.wcqGhostCount,
+		EvictionEpoch:     wec.evictionEpoch,
+		SPQDwellRequests:  wec.spqDwellRequests,
+		SPQDwellIdle:      wec.spqDwellIdle,
+	}
+	ids := map[*WECData]int{}
+	id := func(data *WECData) int {
+		if i, ok := ids[data]; ok {
+			return i
+		}
+		ids[data] = len(state.Data)
+		state.Data = append(state.Data, wecDataState{
+			Address:      data.address,
+			Location:     data.location,
+			AccessCount:  data.accessCount,
+			LastAccess:   data.lastAccess,
+			QuitEpoch:    data.quitEpoch,
+			SPQRequest:   data.spqRequest,
+			SPQIdleEpoch: data.spqIdleEpoch,
+		})
+		return ids[data]
+	}
+	queue := func(om *orderedmap.OrderedMap[*WECData]) (items []int) {
+		iter := om.Iter()
+		for _, data, ok := iter.Next(); ok; _, data, ok = iter.Next() {
+			items = append(items, id(data))
+		}
+		return items
+	}
+
+	state.WCQueue = queue(wec.WCQueue)
+	state.SPQueue = queue(wec.SPQueue)
+	state.RAMQueue = queue(wec.RAMQueue)
+	addresses := make([]int, 0, len(wec.SSDMap))
+	for address := range wec.SSDMap {
+		addresses = append(addresses, address)
+	}
+	sort.Ints(addresses)
+	for _, address := range addresses {
+		state.SSDMap = append(state.SSDMap, id(wec.SSDMap[address]))
+	}
+	for data := range wec.dirty {
+		state.Dirty = append(state.Dirty, id(data))
+	}
+	sort.Ints(state.Dirty)
+	wec.WCQTree.Scan(func(accessCount int, value *orderedmap.OrderedMap[*WECData]) bool {
+		state.WCQTree = append(state.WCQTree, wecGroupState{Key: accessCount, Items: queue(value)})
+		return true
+	})
+	epochs := make([]int, 0, len(wec.spqExpiry))
+	for epoch := range wec.spqExpiry {
+		epochs = append(epochs, epoch)
+	}
+	sort.Ints(epochs)
+	for _, epoch := range epochs {
+		group := wecGroupState{Key: epoch}
+		for _, data := range wec.spqExpiry[epoch] {
+			group.Items = append(group.Items, id(data))
+		}
+		state.SPQExpiry = append(state.SPQExpiry, group)
+	}
+	return enc.Encode(state)
+}
+
+func (wec *WECache) Restore(dec *gob.Decoder) error {
+	var state wecState
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	datas := make([]*WECData, len(state.Data))
+	for i, data := range state.Data {
+		if data.Location < LocationRAM || data.Location > LocationHDD {
+			return fmt.Errorf("wec: snapshot block %d has unknown location %d", data.Address, data.Location)
+		}
+		datas[i] = &WECData{
+			address:      data.Address,
+			location:     data.Location,
+			accessCount:  data.AccessCount,
+			lastAccess:   data.LastAccess,
+			quitEpoch:    data.QuitEpoch,
+			spqRequest:   data.SPQRequest,
+			spqIdleEpoch: data.SPQIdleEpoch,
+		}
+	}
+	var err error
+	lookup := func(items []int) []*WECData {
+		result := make([]*WECData, 0, len(items))
+		for _, item := range items {
+			if item < 0 || item >= len(datas) {
+				err = fmt.Errorf("wec: snapshot refers to block %d of %d", item, len(datas))
+				return nil
+			}
+			result = append(result, datas[item])
+		}
+		return result
+	}
+	queue := func(items []int) *orderedmap.OrderedMap[*WECData] {
+		om := orderedmap.NewPooled[*WECData]()
+		for _, data := range lookup(items) {
+			om.Set(data.address, data)
+		}
+		return om
+	}
+
+	WCQueue := queue(state.WCQueue)
+	SPQueue := queue(state.SPQueue)
+	RAMQueue := queue(state.RAMQueue)
+	SSDMap := make(map[int]*WECData, len(state.SSDMap))
+	for _, data := range lookup(state.SSDMap) {
+		SSDMap[data.address] = data
+	}
+	dirty := make(map[*WECData]struct{}, len(state.Dirty))
+	for _, data := range lookup(state.Dirty) {
+		dirty[data] = struct{}{}
+	}
+	WCQTree := btree.NewMap[int, *orderedmap.OrderedMap[*WECData]](2)
+	for _, group := range state.WCQTree {
+		WCQTree.Set(group.Key, queue(group.Items))
+	}
+	spqExpiry := make(map[int][]*WECData, len(state.SPQExpiry))
+	for _, group := range state.SPQExpiry {
+		spqExpiry[group.Key] = lookup(group.Items)
+	}
+	if err != nil {
+		return err
+	}
+
+	wec.wcqSize = state.WCQSize
+	wec.candidateCount = state.CandidateCount
+	wec.requestCount = state.RequestCount
+	wec.hitCount = state.HitCount
+	wec.missCount = state.MissCount
+	wec.writeCount = state.WriteCount
+	wec.readRequestCount = state.ReadRequestCount
+	wec.writeRequestCount = state.WriteRequestCount
+	wec.ssdHitCount = state.SSDHitCount
+	wec.ramHitCount = state.RAMHitCount
+	wec.wcqGhostCount = state.WCQGhostCount
+	wec.evictionEpoch = state.EvictionEpoch
+	wec.WCQueue = WCQueue
+	wec.SPQueue = SPQueue
+	wec.RAMQueue = RAMQueue
+	wec.SSDMap = SSDMap
+	wec.WCQTree = WCQTree
+	wec.spqExpiry = spqExpiry
+	wec.spqDwellRequests = state.SPQDwellRequests
+	wec.spqDwellIdle = state.SPQDwellIdle
+	wec.dirty = dirty
+	return nil
+}
@@ -0,0 +1,290 @@
+package wec_v5
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ixtza/ajk/wec/simulator"
+	"ixtza/ajk/wec/synthetic"
+)
+
+// syntheticTrace campuran blok populer (80% request di 10% alamat) dan
+// blok acak, sepertiga request adalah write
+func syntheticTrace(seed int64, length, footprint int) []simulator.Trace {
+	random := rand.New(rand.NewSource(seed))
+	traces := make([]simulator.Trace, length)
+	for i := range traces {
+		addr := random.Intn(footprint)
+		if random.Float64() < 0.8 {
+			addr = random.Intn(footprint/10 + 1)
+		}
+		op := "R"
+		if random.Intn(3) == 0 {
+			op = "W"
+		}
+		traces[i] = simulator.Trace{Addr: addr, Op: op}
+	}
+	return traces
+}
+
+func spqKeys(wec *WECache) (keys []int) {
+	iter := wec.SPQueue.Iter()
+	for address, _, ok := iter.Next(); ok; address, _, ok = iter.Next() {
+		keys = append(keys, address)
+	}
+	return keys
+}
+
+func fullScanKeys(wec *fullScanWEC) (keys []int) {
+	iter := wec.SPQueue.Iter()
+	for address, _, ok := iter.Next(); ok; address, _, ok = iter.Next() {
+		keys = append(keys, address.(int))
+	}
+	return keys
+}
+
+// TestEpochMatchesFullScan membandingkan WEC dengan fullScanWEC setiap
+// request (jumlah hit/miss/write, isi SPQueue dan SSD) lalu seluruh
+// laporannya
+func TestEpochMatchesFullScan(t *testing.T) {
+	configs := []struct {
+		capacity int
+		period   int
+		qtType   string
+		ram      float32
+		ratio    float32
+	}{
+		{500, 100, "linear", 0.1, 0.2},
+		{500, 50, "cubic", 0.1, 0.5},
+		{800, 200, "quadratic", 0.2, 0.3},
+		{800, 100, "square_root", 0.1, 0.1},
+		{1000, 300, "cube_root", 0.3, 0.2},
+		{300, 10, "linear", 0.5, 1},
+	}
+	for i, config := range configs {
+		for seed := int64(1); seed <= 3; seed++ {
+			name := fmt.Sprintf("%d-%s-seed%d", i, config.qtType, seed)
+			t.Run(name, func(t *testing.T) {
+				epoch, err := New(config.capacity, config.period, config.qtType, config.ram, config.ratio, 0.5)
+				if err != nil {
+					t.Fatal(err)
+				}
+				old := newFullScan(config.capacity, config.period, config.qtType, config.ram, config.ratio, 0.5).(*fullScanWEC)
+
+				for index, trace := range syntheticTrace(seed, 30000, config.capacity*2) {
+					if err := epoch.Get(trace); err != nil {
+						t.Fatal(err)
+					}
+					if err := old.Get(trace); err != nil {
+						t.Fatal(err)
+					}
+					got := [4]int{epoch.hitCount, epoch.missCount, epoch.writeCount, epoch.ssdHitCount}
+					want := [4]int{old.hitCount, old.missCount, old.writeCount, old.ssdHitCount}
+					if got != want {
+						t.Fatalf("request %d: hit/miss/write/ssd hit %v, full scan %v", index, got, want)
+					}
+					if !slices.Equal(spqKeys(epoch), fullScanKeys(old)) {
+						t.Fatalf("request %d: SPQueue %v, full scan %v", index, spqKeys(epoch), fullScanKeys(old))
+					}
+					if len(epoch.SSDMap) != len(old.SSDMap) {
+						t.Fatalf("request %d: SSD holds %d blocks, full scan %d", index, len(epoch.SSDMap), len(old.SSDMap))
+					}
+				}
+				if got, want := wecReport(t, epoch), wecReport(t, old); got != want {
+					t.Fatalf("epoch:\n%s\nfull scan:\n%s", got, want)
+				}
+				t.Logf("hit %d, miss %d, SPQueue quits %d", epoch.hitCount, epoch.missCount, epoch.spqDwellRequests[spqQuit].Count)
+			})
+		}
+	}
+}
+
+// tierTracker menandai evict untuk blok yang tidak berada di tier yang
+// dilaporkan; cached diisi dari isi WEC sebelum setiap request dan
+// diperbarui oleh event selama request
+type tierTracker struct {
+	cached map[tierBlock]struct{}
+	bogus  []simulator.Event
+}
+
+type tierBlock struct {
+	addr int
+	tier simulator.Tier
+}
+
+func (tracker *tierTracker) Observe(event simulator.Event) {
+	switch event.Kind {
+	case simulator.EventInsert, simulator.EventPromote, simulator.EventDemote:
+		tracker.cached[tierBlock{event.Addr, event.Tier}] = struct{}{}
+	case simulator.EventEvict:
+		block := tierBlock{event.Addr, event.Tier}
+		if _, ok := tracker.cached[block]; !ok {
+			tracker.bogus = append(tracker.bogus, event)
+		}
+		delete(tracker.cached, block)
+	}
+}
+
+// cachedBlocks mengembalikan isi tier WEC: data SSDMap yang berlokasi di
+// SSD dan data RAMQueue yang berlokasi di RAM
+func cachedBlocks(wec *WECache) map[tierBlock]struct{} {
+	blocks := map[tierBlock]struct{}{}
+	iter := wec.RAMQueue.Iter()
+	for address, data, ok := iter.Next(); ok; address, data, ok = iter.Next() {
+		if data.location == LocationRAM {
+			blocks[tierBlock{address, simulator.TierRAM}] = struct{}{}
+		}
+	}
+	for address, data := range wec.SSDMap {
+		if data.location == LocationSSD {
+			blocks[tierBlock{address, simulator.TierSSD}] = struct{}{}
+		}
+	}
+	return blocks
+}
+
+// TestEvictMatchesTier memastikan setiap evict berasal dari tier tempat
+// blok benar-benar berada, sehingga tidak ada blok yang dilaporkan evict
+// dua kali (misalnya saat keluar dari SPQueue setelah dipindah ke HDD)
+func TestEvictMatchesTier(t *testing.T) {
+	configs := []struct {
+		capacity int
+		period   int
+		ram      float32
+		ratio    float32
+	}{
+		{2000, 500, 0.1, 0.3},
+		{1000, 100, 0.3, 0.5},
+		{500, 50, 0.5, 1},
+	}
+	for i, config := range configs {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			wec, err := New(config.capacity, config.period, "linear", config.ram, config.ratio, 0.5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tracker := &tierTracker{}
+			wec.SetObserver(tracker)
+			for index, trace := range syntheticTrace(1, 20000, config.capacity*2) {
+				tracker.cached = cachedBlocks(wec)
+				if err := wec.Get(trace); err != nil {
+					t.Fatal(err)
+				}
+				if len(tracker.bogus) > 0 {
+					t.Fatalf("request %d: evict of block not in tier: %v", index, tracker.bogus)
+				}
+			}
+			if wec.spqDwellRequests[spqQuit].Count == 0 {
+				t.Fatal("no SPQueue quits")
+			}
+			t.Logf("SPQueue quits %d", wec.spqDwellRequests[spqQuit].Count)
+		})
+	}
+}
+
+// wecReport mengembalikan keluaran PrintToFile tanpa baris duration,
+// termasuk baris !WEC
+func wecReport(t testing.TB, sim simulator.Simulator) string {
+	file, err := os.CreateTemp(t.TempDir(), "wec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := sim.PrintToFile(file, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "duration:") {
+			lines = append(lines, line)
+		}
+	}
+	if !strings.Contains(string(data), "\n!WEC|") {
+		t.Fatalf("no !WEC line in %q", data)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestPortMatchesLegacy(t *testing.T) {
+	configs := []struct {
+		capacity int
+		period   int
+		qtType   string
+		ram      float32
+		ratio    float32
+	}{
+		{2000, 1000, "linear", 0.1, 1},
+		{2000, 100, "cubic", 0.2, 0.5},
+		{5000, 500, "square_root", 0.1, 0.3},
+		{1000, 50, "cube_root", 0.3, 0.2},
+	}
+	for _, workload := range synthetic.Workloads {
+		traces, err := synthetic.Trace(workload, 100000, 10000, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, config := range configs {
+			t.Run(fmt.Sprintf("%s-%d", workload, i), func(t *testing.T) {
+				port, err := New(config.capacity, config.period, config.qtType, config.ram, config.ratio, 0.5)
+				if err != nil {
+					t.Fatal(err)
+				}
+				legacy := newLegacy(config.capacity, config.period, config.qtType, config.ram, config.ratio, 0.5)
+				for _, trace := range traces {
+					if err := port.Get(trace); err != nil {
+						t.Fatal(err)
+					}
+					if err := legacy.Get(trace); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if got, want := wecReport(t, port), wecReport(t, legacy); got != want {
+					t.Fatalf("port:\n%s\nlegacy:\n%s", got, want)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkGet membandingkan WEC dengan orderedmap bertipe dan WEC lama
+// dengan secnot/orderedmap pada trace yang sama
+func BenchmarkGet(b *testing.B) {
+	const capacity, footprint = 20000, 200000
+	for _, workload := range []string{"zipf", "uniform"} {
+		traces, err := synthetic.Trace(workload, 2000000, footprint, 0.3, 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		simulators := []struct {
+			name string
+			new  func() simulator.Simulator
+		}{
+			{"orderedmap", func() simulator.Simulator {
+				wec, _ := New(capacity, 1000, "linear", 0.1, 1, 0.5)
+				return wec
+			}},
+			{"secnot", func() simulator.Simulator {
+				return newLegacy(capacity, 1000, "linear", 0.1, 1, 0.5)
+			}},
+		}
+		for _, sim := range simulators {
+			b.Run(workload+"/"+sim.name, func(b *testing.B) {
+				wec := sim.new()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					wec.Get(traces[i%len(traces)])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package wtinylfu
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"ixtza/ajk/wec/algo/admission"
+	"ixtza/ajk/wec/simulator"
+)
+
+const (
+	window = iota
+	probation
+	protected
+)
+
+type (
+	Node struct {
+		lba     int
+		segment int
+	}
+
+	WTinyLFU struct {
+		maxlen        int
+		windowSize    int
+		mainSize      int
+		protectedSize int
+		totalaccess   int
+		hit           int
+		miss          int
+		write         int
+		admitted      int
+		rejected      int
+
+		filter    *admission.TinyLFU
+		index     map[int]*list.Element
+		window    *list.List
+		probation *list.List
+		protected *list.List
+	}
+)
+
+func init() {
+	simulator.Register(simulator.Algorithm{
+		Name:        "wtinylfu",
+		Description: "Window TinyLFU",
+		Params: []simulator.Param{
+			{Name: "wtinylfu-window", Kind: simulator.ParamFloat, Default: 1.0, Usage: "persentase window LRU terhadap cache"},
+		},
+		New: func(cacheSize int, params simulator.Params) (simulator.Simulator, error) {
+			return NewWTinyLFU(cacheSize, params.Float("wtinylfu-window"))
+		},
+	})
+}
+
+// NewWTinyLFU membagi cache menjadi window LRU sebesar windowPercentage
+// persen dan area utama SLRU (20% probation, 80% protected).
+func NewWTinyLFU(cacheSize int, windowPercentage float64) (*WTinyLFU, error) {
+	if cacheSize <= 0 {
+		return nil, fmt.Errorf("wtinylfu: cache size must be positive, got %d", cacheSize)
+	}
+	if windowPercentage < 0 || windowPercentage > 100 {
+		return nil, fmt.Errorf("wtinylfu: window percentage must be between 0 and 100, got %v", windowPercentage)
+	}
+	windowSize := int(float64(cacheSize) * windowPercentage / 100)
+	if windowSize < 1 {
+		windowSize = 1
+	}
+	mainSize := cacheSize - windowSize
+	if mainSize < 0 {
+		mainSize = 0
+	}
+	return &WTinyLFU{
+		maxlen:        cacheSize,
+		windowSize:    windowSize,
+		mainSize:      mainSize,
+		protectedSize: mainSize * 80 / 100,
+		filter:        admission.NewTinyLFU(cacheSize),
+		index:         make(map[int]*list.Element, cacheSize),
+		window:        list.New(),
+		probation:     list.New(),
+		protected:     list.New(),
+	}, nil
+}
+
+func (w *WTinyLFU) Get(trace simulator.Trace) (err error) {
+	w.totalaccess++
+	w.filter.Record(trace.Addr)
+
+	if el, ok := w.index[trace.Addr]; ok {
+		w.hit++
+		if trace.Op == "W" {
+			w.write++
+		}
+		node := el.Value.(*Node)
+		switch node.segment {
+		case window:
+			w.window.MoveToFront(el)
+		case probation:
+			// naik ke protected, turunkan yang paling lama jika penuh
+			w.probation.Remove(el)
+			node.segment = protected
+			w.index[node.lba] = w.protected.PushFront(node)
+			if w.protected.Len() > w.protectedSize {
+				back := w.protected.Back()
+				demoted := w.protected.Remove(back).(*Node)
+				demoted.segment = probation
+				w.index[demoted.lba] = w.probation.PushFront(demoted)
+			}
+		case protected:
+			w.protected.MoveToFront(el)
+		}
+		return nil
+	}
+
+	w.miss++
+	w.write++
+	w.index[trace.Addr] = w.window.PushFront(&Node{lba: trace.Addr, segment: window})
+	if w.window.Len() <= w.windowSize {
+		return nil
+	}
+
+	candidate := w.window.Remove(w.window.Back()).(*Node)
+	if w.probation.Len()+w.protected.Len() < w.mainSize {
+		candidate.segment = probation
+		w.index[candidate.lba] = w.probation.PushFront(candidate)
+		return nil
+	}
+
+	victimList := w.probation
+	if victimList.Len() == 0 {
+		victimList = w.protected
+	}
+	victimEl := victimList.Back()
+	if victimEl == nil {
+		// area utama berukuran nol
+		w.rejected++
+		delete(w.index, candidate.lba)
+		return nil
+	}
+
+	victim := victimEl.Value.(*Node)
+	if w.filter.Admit(candidate.lba, victim.lba, true) {
+		w.admitted++
+		victimList.Remove(victimEl)
+		delete(w.index, victim.lba)
+		candidate.segment = probation
+		w.index[candidate.lba] = w.probation.PushFront(candidate)
+	} else {
+		w.rejected++
+		delete(w.index, candidate.lba)
+	}
+	return nil
+}
+
+func (w *WTinyLFU) PrintToFile(file *os.File, timeStart time.Time) (err error) {
+	duration := time.Since(timeStart)
+	file.WriteString("------------------------------------\n")
+	file.WriteString("W-TinyLFU\n")
+	file.WriteString(fmt.Sprintf("NUM ACCESS: %d\n", w.totalaccess))
+	file.WriteString(fmt.Sprintf("cache size: %d\n", w.maxlen))
+	file.WriteString(fmt.Sprintf("window size: %d\n", w.windowSize))
+	file.WriteString(fmt.Sprintf("probation size: %d\n", w.mainSize-w.protectedSize))
+	file.WriteString(fmt.Sprintf("protected size: %d\n", w.protectedSize))
+	file.WriteString(fmt.Sprintf("cache hit: %d\n", w.hit))
+	file.WriteString(fmt.Sprintf("cache miss: %d\n", w.miss))
+	file.WriteString(fmt.Sprintf("ssd write: %d\n", w.write))
+	file.WriteString(fmt.Sprintf("admitted to main: %d\n", w.admitted))
+	file.WriteString(fmt.Sprintf("rejected from main: %d\n", w.rejected))
+	file.WriteString(fmt.Sprintf("write efficiency : %d\n", (w.hit / w.write)))
+	file.WriteString(fmt.Sprintf("hit ratio : %8.4f\n", (float64(w.hit)/float64(w.totalaccess))*100))
+	file.WriteString(fmt.Sprintf("duration : %v\n", duration.Seconds()))
+
+	_, err = file.WriteString(fmt.Sprintf("!WTINYLFU|%d|%d|%d\n", w.maxlen, w.hit, w.write))
+	return err
+}
+
+func (w *WTinyLFU) Misses() int {
+	return w.miss
+}
+
+func (w *WTinyLFU) Capacity() int {
+	return w.maxlen
+}
+
+func (w *WTinyLFU) Metadata() simulator.Metadata {
+	return simulator.Metadata{
+		Tracked:       len(w.index),
+		BytesPerEntry: int(unsafe.Sizeof(Node{})) + simulator.ListElementBytes + simulator.MapEntryBytes,
+		FixedBytes:    w.filter.Metadata().FixedBytes,
+	}
+}
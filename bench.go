@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "ixtza/ajk/wec/algo"
+	"ixtza/ajk/wec/simulator"
+	"ixtza/ajk/wec/synthetic"
+)
+
+// runBench mengukur throughput (request/detik) dan alokasi per request
+// setiap algoritma pada trace sintetis yang dibangkitkan di memori
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	algos := flags.String("algo", "all", "daftar algoritma dipisah koma")
+	workloads := flags.String("workload", "uniform,zipf,scan", "daftar trace sintetis dipisah koma\n(uniform|zipf|scan)")
+	requests := flags.Int("requests", 1000000, "jumlah request per trace")
+	footprint := flags.Int("footprint", 100000, "jumlah alamat berbeda pada trace")
+	cache := flags.Int("cache", 10000, "ukuran cache")
+	writeRatio := flags.Float64("write-ratio", 0.3, "rasio request W")
+	seed := flags.Int64("seed", 1, "seed pembangkit trace")
+	config := flags.String("config", "", "file sweep, satu konfigurasi per baris:\n<algoritma> [parameter=nilai ...]; menggantikan -algo\ndan flag parameter")
+	// parameter algoritma sama dengan CLI utama, default dari registry
+	algorithmParams := simulator.BindFlags(flags)
+	flags.Parse(args)
+
+	if *requests <= 0 || *footprint <= 0 || *cache <= 0 {
+		return fmt.Errorf("requests, footprint and cache must be positive")
+	}
+
+	var configs []benchConfig
+	if *config != "" {
+		file, err := os.Open(*config)
+		if err != nil {
+			return err
+		}
+		configs, err = parseBenchConfig(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", *config, err)
+		}
+	} else if *algos == "all" {
+		for _, algorithm := range simulator.Algorithms() {
+			configs = append(configs, benchConfig{algorithm.Name, algorithm, algorithmParams.Params(algorithm)})
+		}
+	} else {
+		for _, name := range strings.Split(*algos, ",") {
+			algorithm, ok := simulator.Lookup(strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("algorithm %q not supported", name)
+			}
+			configs = append(configs, benchConfig{algorithm.Name, algorithm, algorithmParams.Params(algorithm)})
+		}
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "algorithm\tworkload\trequests\tseconds\treq/s\tns/req\tallocs/req\t")
+	for _, workload := range strings.Split(*workloads, ",") {
+		traces, err := synthetic.Trace(strings.TrimSpace(workload), *requests, *footprint, *writeRatio, *seed)
+		if err != nil {
+			return err
+		}
+		for _, config := range configs {
+			sim, err := config.algorithm.New(*cache, config.params)
+			if err != nil {
+				return fmt.Errorf("%s: %w", config.name, err)
+			}
+			elapsed, allocs, err := replay(sim, traces)
+			if err != nil {
+				return fmt.Errorf("%s: %w", config.name, err)
+			}
+			fmt.Fprintf(table, "%s\t%s\t%d\t%.3f\t%.0f\t%.1f\t%.2f\t\n",
+				config.name,
+				workload,
+				len(traces),
+				elapsed.Seconds(),
+				float64(len(traces))/elapsed.Seconds(),
+				float64(elapsed.Nanoseconds())/float64(len(traces)),
+				float64(allocs)/float64(len(traces)),
+			)
+		}
+	}
+	return table.Flush()
+}
+
+// benchConfig adalah satu baris tabel: algoritma dengan nilai parameternya
+type benchConfig struct {
+	// name adalah baris konfigurasi apa adanya, atau nama algoritma
+	name      string
+	algorithm simulator.Algorithm
+	params    simulator.Params
+}
+
+// parseBenchConfig membaca file sweep; baris kosong dan baris yang diawali
+// # dilewati, parameter yang tidak disebut memakai default registry
+func parseBenchConfig(r io.Reader) (configs []benchConfig, err error) {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		algorithm, ok := simulator.Lookup(fields[0])
+		if !ok {
+			return nil, fmt.Errorf("line %d: algorithm %q not supported", line, fields[0])
+		}
+		values := make(map[string]string, len(fields)-1)
+		for _, field := range fields[1:] {
+			name, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: %q is not parameter=value", line, field)
+			}
+			if _, ok := values[name]; ok {
+				return nil, fmt.Errorf("line %d: parameter %s given twice", line, name)
+			}
+			values[name] = value
+		}
+		params, err := algorithm.ParseParams(values)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		configs = append(configs, benchConfig{strings.Join(fields, " "), algorithm, params})
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no configuration")
+	}
+	return configs, nil
+}
+
+func replay(sim simulator.Simulator, traces []simulator.Trace) (elapsed time.Duration, allocs uint64, err error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	timeStart := time.Now()
+	for _, trace := range traces {
+		if err = sim.Get(trace); err != nil {
+			return elapsed, allocs, err
+		}
+	}
+	elapsed = time.Since(timeStart)
+	runtime.ReadMemStats(&after)
+	return elapsed, after.Mallocs - before.Mallocs, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBenchConfig(t *testing.T) {
+	configs, err := parseBenchConfig(strings.NewReader(`
+# LIRS dengan dua ukuran partisi HIR
+lirs
+LIRS   lirs-hir=5
+wecv5 wec-qt-type=cubic wec-update-periode=50
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 3 {
+		t.Fatalf("%d configurations, want 3", len(configs))
+	}
+	if configs[0].params.Int("lirs-hir") != 1 || configs[1].params.Int("lirs-hir") != 5 {
+		t.Errorf("lirs-hir %v and %v, want default 1 and 5", configs[0].params["lirs-hir"], configs[1].params["lirs-hir"])
+	}
+	if configs[1].name != "LIRS lirs-hir=5" || configs[1].algorithm.Name != "lirs" {
+		t.Errorf("name %q, algorithm %q", configs[1].name, configs[1].algorithm.Name)
+	}
+	wec := configs[2].params
+	if wec.String("wec-qt-type") != "cubic" || wec.Int("wec-update-periode") != 50 || wec.Float("wec-threshold") != 0.5 {
+		t.Errorf("wecv5 params %v", wec)
+	}
+	if _, err := configs[2].algorithm.New(100, wec); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseBenchConfigErrors(t *testing.T) {
+	for _, config := range []string{
+		"",
+		"# kosong",
+		"lirs\nnope",
+		"lirs lirs-hir",
+		"lirs lirs-hir=x",
+		"lirs wec-threshold=0.5",
+		"lirs lirs-hir=1 lirs-hir=2",
+	} {
+		if _, err := parseBenchConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("%q: no error", config)
+		}
+	}
+}
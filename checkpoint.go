@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Format checkpoint:
+//
+//	magic "WECCKPT" + versi (1 byte) | checkpoint (gob)
+//	| snapshot simulator (simulator.EncodeSnapshot) | state tracker (gob)
+const (
+	checkpointMagic   = "WECCKPT"
+	checkpointVersion = 1
+)
+
+// checkpoint adalah posisi replay; file keluaran dipotong kembali ke
+// ukurannya saat checkpoint ditulis sebelum replay dilanjutkan
+type checkpoint struct {
+	Args    []string
+	Trace   string
+	Records int
+
+	OutPath        string
+	OutSize        int64
+	TimeSeriesPath string
+	TimeSeriesSize int64
+
+	// indeks ukuran cache di daftar dan jumlah request yang sudah diproses
+	Cache   int
+	Request int
+}
+
+// checkpointArgs adalah argumen run tanpa -resume, harus sama antara run
+// yang menulis checkpoint dan run yang melanjutkannya
+func checkpointArgs(args []string) []string {
+	var result []string
+	for _, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if arg != name && (name == "resume" || strings.HasPrefix(name, "resume=")) {
+			continue
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+// saveCheckpoint menulis posisi replay, state simulator dan state tracker
+// (urutan parts harus sama saat dimuat)
+func saveCheckpoint(path string, state checkpoint, header simulator.SnapshotHeader, sim simulator.Simulator, parts []simulator.Snapshotter) error {
+	return simulator.WriteAtomic(path, func(w io.Writer) error {
+		if _, err := w.Write(append([]byte(checkpointMagic), checkpointVersion)); err != nil {
+			return err
+		}
+		enc := gob.NewEncoder(w)
+		if err := enc.Encode(state); err != nil {
+			return err
+		}
+		if err := simulator.EncodeSnapshot(enc, header, sim); err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if err := part.Snapshot(enc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeCheckpoint mencatat ukuran file keluaran setelah memastikan isinya
+// sudah di disk lalu menulis checkpoint
+func writeCheckpoint(path string, state checkpoint, out, timeSeries *os.File, header simulator.SnapshotHeader, sim simulator.Simulator, parts []simulator.Snapshotter) (err error) {
+	if state.OutSize, err = syncedSize(out); err != nil {
+		return err
+	}
+	if timeSeries != nil {
+		state.TimeSeriesPath = timeSeries.Name()
+		if state.TimeSeriesSize, err = syncedSize(timeSeries); err != nil {
+			return err
+		}
+	}
+	state.OutPath = out.Name()
+	return saveCheckpoint(path, state, header, sim, parts)
+}
+
+func syncedSize(file *os.File) (int64, error) {
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+	return file.Seek(0, io.SeekCurrent)
+}
+
+// loadCheckpoint membaca posisi replay; decoder dipakai restoreCheckpoint
+// setelah simulator ukuran cache tersebut dibuat
+func loadCheckpoint(path string) (state checkpoint, dec *gob.Decoder, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(checkpointMagic)) || len(data) <= len(checkpointMagic) {
+		return state, nil, fmt.Errorf("checkpoint: %s: not a checkpoint", path)
+	}
+	if version := data[len(checkpointMagic)]; version != checkpointVersion {
+		return state, nil, fmt.Errorf("checkpoint: %s: unsupported version %d", path, version)
+	}
+	dec = gob.NewDecoder(bytes.NewReader(data[len(checkpointMagic)+1:]))
+	if err = dec.Decode(&state); err != nil {
+		return state, nil, fmt.Errorf("checkpoint: %s: %w", path, err)
+	}
+	return state, dec, nil
+}
+
+func (state checkpoint) check(args []string, trace string, records int) error {
+	if !slices.Equal(state.Args, args) {
+		return fmt.Errorf("checkpoint: written by %q, not %q", strings.Join(state.Args, " "), strings.Join(args, " "))
+	}
+	if state.Trace != trace || state.Records != records {
+		return fmt.Errorf("checkpoint: trace %s has %d records, checkpoint expects %d", trace, records, state.Records)
+	}
+	return nil
+}
+
+func restoreCheckpoint(dec *gob.Decoder, header simulator.SnapshotHeader, sim simulator.Simulator, parts []simulator.Snapshotter) error {
+	if err := simulator.DecodeSnapshot(dec, header, sim); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	for _, part := range parts {
+		if err := part.Restore(dec); err != nil {
+			return fmt.Errorf("checkpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+// reopenOutput membuka kembali file keluaran run yang dilanjutkan dan
+// membuang isi yang ditulis setelah checkpoint
+func reopenOutput(path string, size int64) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = file.Truncate(size); err == nil {
+		_, err = file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
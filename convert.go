@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"ixtza/ajk/wec/tracefile"
+)
+
+// runConvert mengubah trace CSV (atau biner) menjadi trace biner yang
+// dideteksi otomatis oleh -filepath
+func runConvert(args []string) (err error) {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "program convert [flags] [input] [output]")
+		flags.PrintDefaults()
+	}
+	noTime := flags.Bool("no-time", false, "tidak menyimpan timestamp walaupun ada di input")
+	noSize := flags.Bool("no-size", false, "tidak menyimpan ukuran request walaupun ada di input")
+	lenient := flags.Bool("trace-lenient", false, "lewati dan hitung baris trace yang rusak alih-alih berhenti")
+	opMap := flags.String("trace-op-map", "", "pemetaan op tambahan ke R/W, contoh 0=R,1=W")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	options, err := newTraceOptions(*lenient, *opMap)
+	if err != nil {
+		return err
+	}
+	input, err := tracefile.Open(flags.Arg(0), options)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	// kolom opsional ditentukan dari record pertama
+	trace, readErr := input.Read()
+
+	output, err := os.Create(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	// keluaran yang tidak lengkap dibuang agar tidak terbaca sebagai trace
+	// biner yang valid
+	defer func() {
+		if err != nil {
+			output.Close()
+			os.Remove(output.Name())
+		}
+	}()
+
+	writer, err := tracefile.NewBinaryWriter(output, input.HasTime() && !*noTime, input.HasSize() && !*noSize)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for ; readErr != io.EOF; trace, readErr = input.Read() {
+		if readErr != nil {
+			return readErr
+		}
+		if err = writer.Write(trace); err != nil {
+			return err
+		}
+		count++
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	if err = output.Sync(); err != nil {
+		return err
+	}
+	if err = output.Close(); err != nil {
+		return err
+	}
+
+	printConvertSummary(flags.Arg(0), flags.Arg(1), input, count)
+	return nil
+}
+
+func printConvertSummary(inputPath, outputPath string, input *tracefile.File, count int) {
+	inputInfo, _ := os.Stat(inputPath)
+	outputInfo, _ := os.Stat(outputPath)
+	fmt.Printf("%v (%v, compression %v) -> %v\n", inputPath, input.Format, input.Compression, outputPath)
+	fmt.Printf("records : %d\n", count)
+	if stats := input.Stats(); stats.Skipped() > 0 {
+		fmt.Printf("skipped : %d (malformed %d, unknown op %d)\n", stats.Skipped(), stats.Malformed, stats.UnknownOp)
+		for _, parseErr := range stats.Errors {
+			fmt.Printf("  %v\n", parseErr)
+		}
+	}
+	if inputInfo != nil && outputInfo != nil {
+		fmt.Printf("bytes : %d -> %d\n", inputInfo.Size(), outputInfo.Size())
+	}
+}
@@ -0,0 +1,244 @@
+// Package decisionlog merekam event setiap request (hit, insert, evict,
+// perpindahan tier) ke file biner ringkas agar dua run bisa dibandingkan
+// request per request dengan subcommand diff.
+package decisionlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Format:
+//
+//	header  : magic "WECDLOG" + versi (1 byte) | nama algoritma (uvarint + byte) | ukuran cache (uvarint)
+//	event   : kind<<4|tier (1 byte) | [tier asal (1 byte), hanya promote/demote]
+//	          | delta request (uvarint) | delta alamat (varint)
+//	trailer : tag 0 | jumlah event (8 byte LE)
+const (
+	magic   = "WECDLOG"
+	version = 1
+
+	tagEnd = 0
+)
+
+// Writer adalah simulator.Observer yang menulis setiap event ke file
+type Writer struct {
+	file   *os.File
+	writer *bufio.Writer
+
+	count       uint64
+	lastRequest int
+	lastAddr    int
+	buf         []byte
+	// kesalahan tulis pertama, Observe tidak bisa mengembalikan error
+	err error
+}
+
+func Create(path, algorithm string, cacheSize int) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := &Writer{
+		file:   file,
+		writer: bufio.NewWriterSize(file, 1<<16),
+		buf:    make([]byte, 0, 2+2*binary.MaxVarintLen64),
+	}
+	header := append([]byte(magic), version)
+	header = binary.AppendUvarint(header, uint64(len(algorithm)))
+	header = append(header, algorithm...)
+	header = binary.AppendUvarint(header, uint64(cacheSize))
+	if _, err = writer.writer.Write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *Writer) Observe(event simulator.Event) {
+	if writer.err != nil {
+		return
+	}
+	buf := append(writer.buf[:0], byte(event.Kind)<<4|byte(event.Tier))
+	if hasFrom(event.Kind) {
+		buf = append(buf, byte(event.From))
+	}
+	buf = binary.AppendUvarint(buf, uint64(event.Request-writer.lastRequest))
+	buf = binary.AppendVarint(buf, int64(event.Addr-writer.lastAddr))
+	writer.lastRequest, writer.lastAddr = event.Request, event.Addr
+	writer.buf = buf[:0]
+	writer.count++
+	_, writer.err = writer.writer.Write(buf)
+}
+
+func (writer *Writer) Name() string {
+	return writer.file.Name()
+}
+
+// Close menulis trailer dan melaporkan kesalahan tulis yang tertunda
+func (writer *Writer) Close() error {
+	if writer.err == nil {
+		trailer := binary.LittleEndian.AppendUint64([]byte{tagEnd}, writer.count)
+		_, writer.err = writer.writer.Write(trailer)
+	}
+	if writer.err == nil {
+		writer.err = writer.writer.Flush()
+	}
+	if err := writer.file.Close(); writer.err == nil {
+		writer.err = err
+	}
+	return writer.err
+}
+
+func hasFrom(kind simulator.EventKind) bool {
+	return kind == simulator.EventPromote || kind == simulator.EventDemote
+}
+
+// Request adalah seluruh event yang dipicu satu request
+type Request struct {
+	Index  int
+	Events []simulator.Event
+}
+
+// Reader membaca log per request
+type Reader struct {
+	file   *os.File
+	reader *bufio.Reader
+
+	Algorithm string
+	CacheSize int
+
+	count       uint64
+	lastRequest int
+	lastAddr    int
+	pending     *simulator.Event
+	done        bool
+}
+
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := &Reader{file: file, reader: bufio.NewReaderSize(file, 1<<16)}
+	if err = reader.readHeader(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return reader, nil
+}
+
+func (reader *Reader) readHeader() error {
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(reader.reader, header); err != nil {
+		return fmt.Errorf("decisionlog: header: %w", err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return errors.New("decisionlog: not a decision log")
+	}
+	if header[len(magic)] != version {
+		return fmt.Errorf("decisionlog: unsupported version %d", header[len(magic)])
+	}
+	length, err := binary.ReadUvarint(reader.reader)
+	if err != nil {
+		return unexpected(err)
+	}
+	name := make([]byte, length)
+	if _, err = io.ReadFull(reader.reader, name); err != nil {
+		return unexpected(err)
+	}
+	cacheSize, err := binary.ReadUvarint(reader.reader)
+	if err != nil {
+		return unexpected(err)
+	}
+	reader.Algorithm, reader.CacheSize = string(name), int(cacheSize)
+	return nil
+}
+
+// event membaca satu event, io.EOF setelah trailer tervalidasi
+func (reader *Reader) event() (event simulator.Event, err error) {
+	if reader.done {
+		return event, io.EOF
+	}
+	head, err := reader.reader.ReadByte()
+	if err != nil {
+		return event, unexpected(err)
+	}
+	if head == tagEnd {
+		reader.done = true
+		trailer := make([]byte, 8)
+		if _, err = io.ReadFull(reader.reader, trailer); err != nil {
+			return event, unexpected(err)
+		}
+		if count := binary.LittleEndian.Uint64(trailer); count != reader.count {
+			return event, fmt.Errorf("decisionlog: trailer has %d events, read %d", count, reader.count)
+		}
+		return event, io.EOF
+	}
+	event.Kind, event.Tier = simulator.EventKind(head>>4), simulator.Tier(head&0x0f)
+	if hasFrom(event.Kind) {
+		from, err := reader.reader.ReadByte()
+		if err != nil {
+			return event, unexpected(err)
+		}
+		event.From = simulator.Tier(from)
+	}
+	delta, err := binary.ReadUvarint(reader.reader)
+	if err != nil {
+		return event, unexpected(err)
+	}
+	addr, err := binary.ReadVarint(reader.reader)
+	if err != nil {
+		return event, unexpected(err)
+	}
+	reader.lastRequest += int(delta)
+	reader.lastAddr += int(addr)
+	event.Request, event.Addr = reader.lastRequest, reader.lastAddr
+	reader.count++
+	return event, nil
+}
+
+// Next mengembalikan event request berikutnya yang memiliki event
+func (reader *Reader) Next() (request Request, err error) {
+	if reader.pending == nil {
+		event, err := reader.event()
+		if err != nil {
+			return request, err
+		}
+		reader.pending = &event
+	}
+	request.Index = reader.pending.Request
+	request.Events = append(request.Events, *reader.pending)
+	reader.pending = nil
+	for {
+		event, err := reader.event()
+		if err == io.EOF {
+			return request, nil
+		}
+		if err != nil {
+			return request, err
+		}
+		if event.Request != request.Index {
+			reader.pending = &event
+			return request, nil
+		}
+		request.Events = append(request.Events, event)
+	}
+}
+
+func (reader *Reader) Close() error {
+	return reader.file.Close()
+}
+
+func unexpected(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("decisionlog: truncated log: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ixtza/ajk/wec/decisionlog"
+	"ixtza/ajk/wec/simulator"
+)
+
+// runDiff membandingkan dua decision log (-decision-log) dan menampilkan
+// request pertama yang berbeda beserta request di sekitarnya; diverged
+// bernilai true bila ada perbedaan
+func runDiff(args []string) (diverged bool, err error) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "program diff [flags] [log a] [log b]")
+		flags.PrintDefaults()
+	}
+	context := flags.Int("context", 3, "jumlah request yang ditampilkan sebelum dan sesudah perbedaan pertama")
+	flags.Parse(args)
+	if flags.NArg() != 2 || *context < 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := decisionlog.Open(flags.Arg(0))
+	if err != nil {
+		return false, err
+	}
+	defer a.Close()
+	b, err := decisionlog.Open(flags.Arg(1))
+	if err != nil {
+		return false, err
+	}
+	defer b.Close()
+
+	fmt.Printf("a : %v (%v, cache %v)\n", flags.Arg(0), a.Algorithm, a.CacheSize)
+	fmt.Printf("b : %v (%v, cache %v)\n", flags.Arg(1), b.Algorithm, b.CacheSize)
+
+	pairs, err := newRequestPairs(a, b)
+	if err != nil {
+		return false, err
+	}
+	var (
+		before    []requestPair
+		after     int
+		requests  int
+		divergent int
+	)
+	for {
+		pair, err := pairs.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return divergent > 0, err
+		}
+		requests++
+		if !pair.equal() {
+			divergent++
+		}
+		switch {
+		case divergent == 0:
+			// simpan context sebelum perbedaan pertama
+			before = append(before, pair)
+			if len(before) > *context {
+				before = before[1:]
+			}
+		case divergent == 1 && !pair.equal():
+			fmt.Printf("first divergence at request %v\n", pair.index)
+			for _, previous := range before {
+				previous.print()
+			}
+			pair.print()
+			after = *context
+		case after > 0:
+			pair.print()
+			after--
+		}
+	}
+
+	if divergent == 0 {
+		fmt.Printf("identical : %v requests\n", requests)
+		return false, nil
+	}
+	fmt.Printf("divergent : %v of %v requests\n", divergent, requests)
+	return true, nil
+}
+
+// requestPair adalah event satu request di kedua log; nil bila request
+// tidak ada di salah satu log
+type requestPair struct {
+	index int
+	a, b  []simulator.Event
+}
+
+func (pair requestPair) equal() bool {
+	if pair.a == nil || pair.b == nil || len(pair.a) != len(pair.b) {
+		return false
+	}
+	for i := range pair.a {
+		if pair.a[i] != pair.b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (pair requestPair) print() {
+	if pair.equal() {
+		fmt.Printf("  %8d    %v\n", pair.index, formatEvents(pair.a))
+		return
+	}
+	fmt.Printf("> %8d a: %v\n", pair.index, formatEvents(pair.a))
+	fmt.Printf("  %8s b: %v\n", "", formatEvents(pair.b))
+	if pair.a != nil && pair.b != nil {
+		i := 0
+		for i < len(pair.a) && i < len(pair.b) && pair.a[i] == pair.b[i] {
+			i++
+		}
+		fmt.Printf("  %8s event %v: a: %v, b: %v\n", "", i+1, formatEvents(pair.a[i:min(i+1, len(pair.a))]), formatEvents(pair.b[i:min(i+1, len(pair.b))]))
+	}
+}
+
+func formatEvents(events []simulator.Event) string {
+	if events == nil {
+		return "(none)"
+	}
+	texts := make([]string, len(events))
+	for i, event := range events {
+		texts[i] = event.String()
+	}
+	return strings.Join(texts, "; ")
+}
+
+// requestPairs menyejajarkan kedua log berdasarkan nomor request
+type requestPairs struct {
+	readers  [2]*decisionlog.Reader
+	current  [2]decisionlog.Request
+	finished [2]bool
+}
+
+func newRequestPairs(a, b *decisionlog.Reader) (*requestPairs, error) {
+	pairs := &requestPairs{readers: [2]*decisionlog.Reader{a, b}}
+	for i := range pairs.readers {
+		if err := pairs.advance(i); err != nil {
+			return nil, err
+		}
+	}
+	return pairs, nil
+}
+
+func (pairs *requestPairs) advance(i int) (err error) {
+	pairs.current[i], err = pairs.readers[i].Next()
+	if err == io.EOF {
+		pairs.finished[i] = true
+		return nil
+	}
+	return err
+}
+
+func (pairs *requestPairs) next() (pair requestPair, err error) {
+	switch {
+	case pairs.finished[0] && pairs.finished[1]:
+		return pair, io.EOF
+	case pairs.finished[1]:
+		pair.index = pairs.current[0].Index
+	case pairs.finished[0]:
+		pair.index = pairs.current[1].Index
+	default:
+		pair.index = min(pairs.current[0].Index, pairs.current[1].Index)
+	}
+	for i := range pairs.readers {
+		if pairs.finished[i] || pairs.current[i].Index != pair.index {
+			continue
+		}
+		if i == 0 {
+			pair.a = pairs.current[i].Events
+		} else {
+			pair.b = pairs.current[i].Events
+		}
+		if err = pairs.advance(i); err != nil {
+			return pair, err
+		}
+	}
+	return pair, nil
+}
@@ -1,26 +1,26 @@
 package main
 
 import (
-	"bufio"
+	"encoding/gob"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"ixtza/ajk/wec/algo/lfu"
-	"ixtza/ajk/wec/algo/lirs"
-	"ixtza/ajk/wec/algo/lru"
-	"ixtza/ajk/wec/algo/wec_v5"
+	_ "ixtza/ajk/wec/algo"
+	"ixtza/ajk/wec/algo/admission"
+	"ixtza/ajk/wec/decisionlog"
 	"ixtza/ajk/wec/simulator"
+	"ixtza/ajk/wec/tracefile"
 )
 
 func main() {
 	var (
 		traces    []simulator.Trace = make([]simulator.Trace, 0)
-		simulator simulator.Simulator
 		timeStart time.Time
 		out       *os.File
 		fs        os.FileInfo
@@ -31,19 +31,66 @@ func main() {
 		cacheList []int
 	)
 
-	algo := flag.String("algo", "", "algorithm\n(LIRS|LRU|LFU|WEC)")
-	pathfile := flag.String("filepath", "", "lokasi file trace dalam direktori")
-	updatingPeriod := flag.Int("wec-update-periode", 0, "periode pembaruan cache")
-	quitThresholdType := flag.String("wec-qt-type", "", "tipe konfigurasi batas umur cache\n(cube-root|square-root|cubic|quadratic|linear)")
-	ramPercentage := flag.Float64("wec-ram-percentage", 0, "rasio ram terhadap cache")
-	capacitySizeRatio := flag.Float64("wec-capacity-ratio", 0, "rasio cache terhadap memori")
-	wecDataThreshold := flag.Float64("wec-threshold", 0, "batas rasio pengambilan kandidat cache")
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		if err := runBench(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		if err := runConvert(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		diverged, err := runDiff(os.Args[2:])
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		if diverged {
+			os.Exit(1)
+		}
+		return
+	}
+
+	algo := flag.String("algo", "", "algorithm, list untuk menampilkan parameter setiap algoritma\n("+strings.Join(simulator.Names(), "|")+")")
+	pathfile := flag.String("filepath", "", "lokasi file trace dalam direktori, - untuk stdin\n(csv|biner, boleh dikompresi gzip|bzip2|zstd|xz|lz4)")
+	traceLenient := flag.Bool("trace-lenient", false, "lewati dan hitung baris trace yang rusak alih-alih berhenti")
+	traceOpMap := flag.String("trace-op-map", "", "pemetaan op tambahan ke R/W, contoh 0=R,1=W\n(r|read|w|write sudah dikenali)")
+	algorithmParams := simulator.BindFlags(flag.CommandLine)
+	events := flag.Bool("events", false, "tulis jumlah event (hit, miss, insert, evict, promote, demote, ssd-write) per tier")
+	classifyMisses := flag.Bool("classify-misses", false, "bagi miss menjadi cold, capacity dan policy dengan pembanding cache OPT")
+	residency := flag.Bool("residency", false, "tulis lama tinggal blok per tier sebelum evict, eviksi prematur dan lama tinggal di SPQueue (WEC)")
+	prematureWindow := flag.Int("premature-window", 1000, "jarak request maksimal referensi ulang setelah evict yang dihitung sebagai eviksi prematur")
+	decisionLog := flag.Bool("decision-log", false, "rekam event setiap request ke log biner per ukuran cache, dibandingkan dengan program diff")
+	saveState := flag.String("save-state", "", "simpan state cache di akhir run ke <nama>_<ukuran cache>.snap\n(hanya "+strings.Join(simulator.SnapshotNames(), "|")+", tanpa -admission)")
+	loadState := flag.String("load-state", "", "mulai run dari state <nama>_<ukuran cache>.snap hasil -save-state")
+	crashEvery := flag.Int("crash-every", 0, "simulasikan crash (RAM dikosongkan) setiap N request (0 = nonaktif)")
+	crashAt := flag.String("crash-at", "", "simulasikan crash saat timestamp trace mencapai nilai ini, dipisah koma")
+	crashWindow := flag.Int("crash-window", 1000, "panjang window request rasio hit untuk mengukur waktu pulih setelah crash")
+	crashMinSample := flag.Int("crash-min-sample", 100, "jumlah request minimal setelah crash sebelum rasio hit dibandingkan (1..crash-window)")
+	checkpointPath := flag.String("checkpoint", "", "file checkpoint untuk -checkpoint-every dan -resume\n(hanya "+strings.Join(simulator.SnapshotNames(), "|")+", tanpa -admission)")
+	checkpointEvery := flag.Int("checkpoint-every", 0, "simpan checkpoint setiap N request (0 = nonaktif)")
+	resume := flag.Bool("resume", false, "lanjutkan run dari -checkpoint, argumen lain harus sama dengan run sebelumnya")
+	timeSeriesInterval := flag.Int("timeseries-interval", 0, "interval request penulisan time series (0 = nonaktif)")
 	baseDir := flag.String("basedir", "", "lokasi dasar penyimpanan keluaran")
+	admissionPolicy := flag.String("admission", "", "admission policy sebelum penulisan ke SSD ("+strings.Join(admissionNames(), "|")+")\n(always|second-hit|n-hit|bloom|probabilistic|tinylfu)")
+	admissionHits := flag.Int("admission-n", 2, "jumlah akses minimal untuk n-hit")
+	admissionWindow := flag.Int("admission-window", 0, "panjang window request untuk n-hit/second-hit (0 = tanpa batas)")
+	admissionProbability := flag.Float64("admission-probability", 0.5, "peluang penerimaan untuk probabilistic")
+	admissionSeed := flag.Int64("admission-seed", 1, "seed random untuk probabilistic")
 
 	flag.Parse()
 
+	if *algo == "list" {
+		simulator.PrintAlgorithms(os.Stdout)
+		return
+	}
+
 	if len(os.Args) < 4 {
-		fmt.Println("program [algorithm(LIRS|LRU|LFU|WEC)] [file] [trace size]...")
+		fmt.Println("program -algo <algorithm> -filepath <trace> [flags] <cache size>...")
+		fmt.Println("algorithm: " + strings.Join(simulator.Names(), "|") + ", -algo list shows their parameters")
 		os.Exit(1)
 	}
 
@@ -53,10 +100,21 @@ func main() {
 	flag.Parse()
 	capacitySize := flag.Args()
 
-	if fs, err = os.Stat(filePath); os.IsNotExist(err) {
-		fmt.Printf("%v does not exists\n", filePath)
+	registered, ok := simulator.Lookup(algorithm)
+	if !ok {
+		fmt.Printf("algorithm %q not supported, -algo list shows every algorithm\n", algorithm)
 		os.Exit(1)
 	}
+	params := algorithmParams.Params(registered)
+
+	fileName := "stdin"
+	if filePath != tracefile.Stdin {
+		if fs, err = os.Stat(filePath); os.IsNotExist(err) {
+			fmt.Printf("%v does not exists\n", filePath)
+			os.Exit(1)
+		}
+		fileName = strings.Split(fs.Name(), ".")[0]
+	}
 
 	cacheList, err = validateTraceSize(capacitySize)
 	if err != nil {
@@ -64,12 +122,74 @@ func main() {
 		os.Exit(1)
 	}
 
-	traces, err = readFile(filePath)
+	// dukungan snapshot diperiksa sebelum trace (bisa beberapa GB) dibaca
+	if *saveState != "" || *loadState != "" || *checkpointPath != "" {
+		if *admissionPolicy != "" {
+			log.Fatal("cache state snapshots and checkpoints are not supported with admission policy")
+		}
+		sim, err := registered.New(cacheList[0], params)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		if _, ok := sim.(simulator.Snapshotter); !ok {
+			log.Fatalf("cache state snapshots and checkpoints are not supported for %v (only %v)", algorithm, strings.Join(simulator.SnapshotNames(), "|"))
+		}
+	}
+
+	traceOptions, err := newTraceOptions(*traceLenient, *traceOpMap)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	traces, traceStats, err := tracefile.ReadAll(filePath, traceOptions)
 	if err != nil {
 		log.Fatalf("error reading file: %v", err)
 	}
+	if skipped := traceStats.Skipped(); skipped > 0 {
+		log.Printf("skipped %d malformed trace lines, first: %v", skipped, traceStats.Errors[0])
+	}
+
+	crashTimes, err := parseCrashTimes(*crashAt)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if len(crashTimes) > 0 && !hasTime(traces) {
+		log.Fatal("crash-at needs a trace with timestamps")
+	}
+	if *crashEvery < 0 || *crashWindow <= 0 {
+		log.Fatal("crash-every must not be negative and crash-window must be positive")
+	}
+	if *crashMinSample < 1 || *crashMinSample > *crashWindow {
+		log.Fatal("crash-min-sample must be between 1 and crash-window")
+	}
+	crashing := *crashEvery > 0 || len(crashTimes) > 0
+	if *prematureWindow <= 0 {
+		log.Fatal("premature-window must be positive")
+	}
+
+	if (*checkpointEvery != 0 || *resume) && *checkpointPath == "" {
+		log.Fatal("checkpoint-every and resume need -checkpoint")
+	}
+	if *checkpointEvery < 0 {
+		log.Fatal("checkpoint-every must not be negative")
+	}
+	if *checkpointPath != "" && *decisionLog {
+		log.Fatal("decision log is not supported with checkpoints")
+	}
+	args := checkpointArgs(os.Args[1:])
+	var (
+		resumed        checkpoint
+		resumedDecoder *gob.Decoder
+	)
+	if *resume {
+		resumed, resumedDecoder, err = loadCheckpoint(*checkpointPath)
+		if err == nil {
+			err = resumed.check(args, filePath, len(traces))
+		}
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
 
-	fileName := strings.Split(fs.Name(), ".")[0]
 	basePath := fmt.Sprintf("./output/%v", algorithm)
 	if baseDirectory != "" {
 		basePath = fmt.Sprintf("%v/%v", basePath, basePath)
@@ -80,65 +200,239 @@ func main() {
 	}
 
 	outPath = fmt.Sprintf("%v/%v_%v_%v.txt", basePath, time.Now().Unix(), algorithm, fileName)
-	if strings.ToLower(algorithm) == "wecv5" {
-		outPath = fmt.Sprintf("%v/%v_%v_%v_%v_%v_%v_%v.txt", basePath, algorithm, fileName, *capacitySizeRatio, *ramPercentage, *quitThresholdType, *updatingPeriod, time.Now().Unix())
+	if len(registered.OutputTags) > 0 {
+		tags := []string{algorithm, fileName}
+		for _, tag := range registered.OutputTags {
+			tags = append(tags, fmt.Sprint(params[tag]))
+		}
+		outPath = fmt.Sprintf("%v/%v_%v.txt", basePath, strings.Join(tags, "_"), time.Now().Unix())
 	}
 
-	out, err = os.Create(outPath)
-	if err != nil {
-		log.Fatal(err.Error())
+	var timeSeries *os.File
+	if *resume {
+		// keluaran run sebelumnya dilanjutkan dari ukurannya saat checkpoint
+		outPath = resumed.OutPath
+		if out, err = reopenOutput(outPath, resumed.OutSize); err != nil {
+			log.Fatal(err.Error())
+		}
+		if resumed.TimeSeriesPath != "" {
+			if timeSeries, err = reopenOutput(resumed.TimeSeriesPath, resumed.TimeSeriesSize); err != nil {
+				log.Fatal(err.Error())
+			}
+		}
+	} else {
+		out, err = os.Create(outPath)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		traceStats.PrintToFile(out, filePath)
+		if *timeSeriesInterval > 0 {
+			timeSeries, err = os.Create(strings.TrimSuffix(outPath, ".txt") + "_timeseries.csv")
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			timeSeries.WriteString("cache,request,metric,value\n")
+		}
 	}
 	defer out.Close()
+	if timeSeries != nil {
+		defer timeSeries.Close()
+	}
 
-	if strings.ToLower(algorithm) == "wecv5" {
-		for _, cache := range cacheList {
-			simulator := wec_v5.New(
-				cache,
-				*updatingPeriod,
-				*quitThresholdType,
-				float32(*ramPercentage),
-				float32(*capacitySizeRatio),
-				float32(*wecDataThreshold),
-			)
-			timeStart = time.Now()
-
-			for _, trace := range traces {
-				err = simulator.Get(trace)
-				if err != nil {
-					log.Fatal(err.Error())
-				}
+	// parameter simulator baru divalidasi saat dibuat, keluaran kosong
+	// dibuang bila gagal kecuali keluaran run yang dilanjutkan
+	var decisionLogs []string
+	invalid := func(err error) {
+		if *resume {
+			log.Fatal(err.Error())
+		}
+		out.Close()
+		os.Remove(outPath)
+		if timeSeries != nil {
+			timeSeries.Close()
+			os.Remove(timeSeries.Name())
+		}
+		for _, path := range decisionLogs {
+			os.Remove(path)
+		}
+		log.Fatal(err.Error())
+	}
+
+	if *admissionPolicy != "" && !registered.Admission {
+		invalid(fmt.Errorf("admission policy is not supported for %v", algorithm))
+	}
+	if *admissionPolicy != "" && (*saveState != "" || *loadState != "") {
+		invalid(fmt.Errorf("cache state snapshots are not supported with admission policy"))
+	}
+	for cacheIndex, cache := range cacheList {
+		if *resume && cacheIndex < resumed.Cache {
+			continue
+		}
+		sim, err := registered.New(cache, params)
+		if err != nil {
+			invalid(err)
+		}
+		if *admissionPolicy != "" {
+			policy, err := newAdmissionPolicy(*admissionPolicy, cache, *admissionHits, *admissionWindow, *admissionProbability, *admissionSeed)
+			if err != nil {
+				invalid(err)
+			}
+			admittable, ok := sim.(admission.Admittable)
+			if !ok {
+				invalid(fmt.Errorf("admission policy is not supported for %v: %T has no Contains/Victim", algorithm, sim))
+			}
+			sim = admission.NewAdmission(strings.ToUpper(algorithm), cache, admittable, policy)
+		}
+		snapshotHeader := simulator.NewSnapshotHeader(registered, cache, params)
+		if *loadState != "" {
+			if err = simulator.LoadSnapshot(snapshotPath(*loadState, cache), snapshotHeader, sim); err != nil {
+				invalid(err)
+			}
+		}
+		if _, ok := sim.(simulator.Snapshotter); (*saveState != "" || *checkpointPath != "") && !ok {
+			invalid(fmt.Errorf("cache state snapshots are not supported for %v", algorithm))
+		}
+
+		metadata := newMetadataTracker(sim)
+		var missClassifier *simulator.MissClassifier
+		if *classifyMisses {
+			if missClassifier = simulator.NewMissClassifier(sim, traces); missClassifier == nil {
+				invalid(fmt.Errorf("miss classification is not supported for %v", algorithm))
+			}
+		}
+		var (
+			observers     simulator.Observers
+			eventCounter  *simulator.EventCounter
+			logWriter     *decisionlog.Writer
+			crashTracker  *simulator.CrashTracker
+			crashSchedule *simulator.CrashSchedule
+			residencies   *simulator.ResidencyTracker
+		)
+		if *events {
+			eventCounter = &simulator.EventCounter{}
+			observers = append(observers, eventCounter)
+		}
+		if *decisionLog {
+			if _, ok := sim.(simulator.Observable); !ok {
+				invalid(fmt.Errorf("decision log is not supported for %v", algorithm))
+			}
+			logWriter, err = decisionlog.Create(fmt.Sprintf("%v_%v.dlog", strings.TrimSuffix(outPath, ".txt"), cache), algorithm, cache)
+			if err != nil {
+				invalid(err)
+			}
+			decisionLogs = append(decisionLogs, logWriter.Name())
+			observers = append(observers, logWriter)
+		}
+		crasher, crashable := sim.(simulator.Crashable)
+		if crashing {
+			if !crashable {
+				invalid(fmt.Errorf("crash simulation is not supported for %v", algorithm))
+			}
+			crashTracker = simulator.NewCrashTracker(*crashWindow, *crashMinSample)
+			crashSchedule = simulator.NewCrashSchedule(*crashEvery, crashTimes)
+			observers = append(observers, crashTracker)
+		}
+		if *residency {
+			if _, ok := sim.(simulator.Observable); !ok {
+				invalid(fmt.Errorf("residency is not supported for %v", algorithm))
 			}
+			residencies = simulator.NewResidencyTracker(sim, *prematureWindow, hasTime(traces))
+			observers = append(observers, residencies)
+		}
+		if !setObservers(sim, observers) {
+			eventCounter = nil
+		}
 
-			simulator.PrintToFile(out, timeStart)
+		// state tracker ikut disimpan agar hasil run yang dilanjutkan sama
+		var parts []simulator.Snapshotter
+		if metadata != nil {
+			parts = append(parts, metadata)
 		}
-	} else {
-		for _, cache := range cacheList {
-			switch strings.ToLower(algorithm) {
-			case "lirs":
-				simulator = lirs.NewLIRS(cache, 1)
-			case "lru":
-				simulator = lru.NewLRU(cache)
-			case "lfu":
-				simulator = lfu.NewLFU(cache)
-			default:
-				log.Fatal("algorithm not supported")
-			}
-
-			timeStart = time.Now()
-
-			for _, trace := range traces {
-				err = simulator.Get(trace)
-				if err != nil {
+		if eventCounter != nil {
+			parts = append(parts, eventCounter)
+		}
+		if missClassifier != nil {
+			parts = append(parts, missClassifier)
+		}
+		if crashTracker != nil {
+			parts = append(parts, crashTracker, crashSchedule)
+		}
+		if residencies != nil {
+			parts = append(parts, residencies)
+		}
+		start := 0
+		if *resume && cacheIndex == resumed.Cache {
+			if err = restoreCheckpoint(resumedDecoder, snapshotHeader, sim, parts); err != nil {
+				log.Fatal(err.Error())
+			}
+			start = resumed.Request
+		}
+		timeStart = time.Now()
+
+		for i := start; i < len(traces); i++ {
+			trace := traces[i]
+			if crashSchedule.Due(i, trace.Time) {
+				crashTracker.Crashed(i, trace.Time, crasher.Crash())
+			}
+			if residencies != nil {
+				residencies.Begin(i, trace)
+			}
+			err = sim.Get(trace)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			if crashTracker != nil {
+				crashTracker.Request(trace.Time)
+			}
+			missClassifier.Request(i)
+			metadata.Observe()
+			if timeSeries != nil && (i+1)%*timeSeriesInterval == 0 {
+				writeSample(timeSeries, sim, cache, i+1)
+			}
+			if *checkpointEvery > 0 && (i+1)%*checkpointEvery == 0 && i+1 < len(traces) {
+				state := checkpoint{Args: args, Trace: filePath, Records: len(traces), Cache: cacheIndex, Request: i + 1}
+				if err = writeCheckpoint(*checkpointPath, state, out, timeSeries, snapshotHeader, sim, parts); err != nil {
 					log.Fatal(err.Error())
 				}
 			}
+		}
 
-			simulator.PrintToFile(out, timeStart)
+		sim.PrintToFile(out, timeStart)
+		metadata.PrintToFile(out, cache)
+		eventCounter.PrintToFile(out, cache)
+		missClassifier.PrintToFile(out, cache)
+		crashTracker.PrintToFile(out, cache)
+		residencies.PrintToFile(out, cache)
+		if *saveState != "" {
+			if err = simulator.SaveSnapshot(snapshotPath(*saveState, cache), snapshotHeader, sim); err != nil {
+				log.Fatal(err.Error())
+			}
+		}
+		if logWriter != nil {
+			if err = logWriter.Close(); err != nil {
+				log.Fatal(err.Error())
+			}
 		}
 	}
 
+	if *checkpointPath != "" {
+		// run selesai, checkpoint tidak diperlukan lagi
+		os.Remove(*checkpointPath)
+	}
+
 	fmt.Println(algorithm)
 	fmt.Println(outPath)
+	if timeSeries != nil {
+		fmt.Println(timeSeries.Name())
+	}
+	for _, path := range decisionLogs {
+		fmt.Println(path)
+	}
+	if *saveState != "" {
+		for _, cache := range cacheList {
+			fmt.Println(snapshotPath(*saveState, cache))
+		}
+	}
 	fmt.Println("Done")
 }
 
@@ -153,39 +447,112 @@ func validateTraceSize(tracesize []string) (sizeList []int, err error) {
 		if err != nil {
 			return sizeList, err
 		}
+		if cache <= 0 {
+			return sizeList, fmt.Errorf("trace size must be positive, got %d", cache)
+		}
 		cacheList = append(cacheList, cache)
 	}
 	return cacheList, nil
 }
 
-func readFile(filePath string) (traces []simulator.Trace, err error) {
-	var (
-		file    *os.File
-		scanner *bufio.Scanner
-		row     []string
-		address int
-	)
-	file, err = os.Open(filePath)
-	if err != nil {
-		return traces, err
+// parseCrashTimes membaca daftar timestamp crash dipisah koma
+func parseCrashTimes(s string) (times []int64, err error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	for _, field := range strings.Split(s, ",") {
+		time, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("crash-at: %w", err)
+		}
+		times = append(times, time)
 	}
-	defer file.Close()
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times, nil
+}
 
-	scanner = bufio.NewScanner(file)
+func hasTime(traces []simulator.Trace) bool {
+	for _, trace := range traces {
+		if trace.Time != 0 {
+			return true
+		}
+	}
+	return false
+}
 
-	for scanner.Scan() {
-		row = strings.Split(scanner.Text(), ",")
-		address, err = strconv.Atoi(row[0])
-		if err != nil {
-			return traces, err
+// snapshotPath adalah file state untuk satu ukuran cache
+func snapshotPath(name string, cache int) string {
+	return fmt.Sprintf("%v_%v.snap", strings.TrimSuffix(name, ".snap"), cache)
+}
+
+func newTraceOptions(lenient bool, opMap string) (options tracefile.Options, err error) {
+	options.Lenient = lenient
+	options.OpMap, err = tracefile.ParseOpMap(opMap)
+	return options, err
+}
+
+// admissionNames adalah algoritma yang bisa dibungkus admission policy
+func admissionNames() (names []string) {
+	for _, algorithm := range simulator.Algorithms() {
+		if algorithm.Admission {
+			names = append(names, strings.ToUpper(algorithm.Name))
 		}
-		traces = append(traces,
-			simulator.Trace{
-				Addr: address,
-				Op:   row[1],
-			},
-		)
 	}
+	return names
+}
+
+func newAdmissionPolicy(name string, cacheSize, hits, window int, probability float64, seed int64) (policy admission.Policy, err error) {
+	switch strings.ToLower(name) {
+	case "always":
+		return admission.NewAlways(), nil
+	case "second-hit":
+		return admission.NewSecondHit(window), nil
+	case "n-hit":
+		if hits < 1 {
+			return nil, fmt.Errorf("admission-n must be positive, got %d", hits)
+		}
+		return admission.NewNHit(hits, window), nil
+	case "bloom":
+		return admission.NewBloom(cacheSize), nil
+	case "probabilistic":
+		if probability < 0 || probability > 1 {
+			return nil, fmt.Errorf("admission-probability must be between 0 and 1, got %v", probability)
+		}
+		return admission.NewProbabilistic(probability, seed), nil
+	case "tinylfu":
+		return admission.NewTinyLFU(cacheSize), nil
+	}
+	return nil, fmt.Errorf("admission policy %q not supported", name)
+}
+
+func newMetadataTracker(sim simulator.Simulator) *simulator.MetadataTracker {
+	return simulator.NewMetadataTracker(sim)
+}
+
+// setObservers memasang observer bila simulator melaporkan event
+func setObservers(sim simulator.Simulator, observers simulator.Observers) bool {
+	if len(observers) == 0 {
+		return false
+	}
+	observable, ok := sim.(simulator.Observable)
+	if !ok {
+		log.Printf("%T does not emit events", sim)
+		return false
+	}
+	if len(observers) == 1 {
+		observable.SetObserver(observers[0])
+	} else {
+		observable.SetObserver(observers)
+	}
+	return true
+}
 
-	return traces, nil
+func writeSample(file *os.File, sim simulator.Simulator, cache, request int) {
+	sampler, ok := sim.(simulator.Sampler)
+	if !ok {
+		return
+	}
+	for _, metric := range sampler.Sample() {
+		file.WriteString(fmt.Sprintf("%d,%d,%s,%v\n", cache, request, metric.Name, metric.Value))
+	}
 }
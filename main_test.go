@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// TestRejectsEmptyCache memastikan setiap algoritma terdaftar menolak
+// ukuran cache yang tidak positif alih-alih panic atau berputar terus
+// saat Get
+func TestRejectsEmptyCache(t *testing.T) {
+	for _, algorithm := range simulator.Algorithms() {
+		for _, cacheSize := range []int{0, -1} {
+			if _, err := algorithm.New(cacheSize, algorithm.Defaults()); err == nil {
+				t.Errorf("%s: cache size %d accepted", algorithm.Name, cacheSize)
+			}
+		}
+	}
+}
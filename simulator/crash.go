@@ -0,0 +1,269 @@
+package simulator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// Crashable diimplementasikan simulator yang memiliki tier volatile
+// (RAM); Crash mengosongkan tier tersebut seperti saat listrik padam
+type Crashable interface {
+	Crash() CrashResult
+}
+
+// CrashResult adalah jumlah blok yang hilang dari tier volatile; Dirty
+// adalah blok yang sudah ditulis tetapi belum sampai ke tier persisten
+// bila cache memakai write-back
+type CrashResult struct {
+	Cleared int
+	Dirty   int
+}
+
+type crash struct {
+	CrashResult
+	request int
+	time    int64
+	// rasio hit window sebelum crash
+	before float64
+	// jumlah request dan selisih timestamp sampai pulih, -1 bila belum
+	recovery     int
+	recoveryTime int64
+}
+
+// CrashTracker mencatat setiap crash dan mengukur waktu pulih: jumlah
+// request setelah crash sampai rasio hit window (paling sedikit minSample
+// request sejak crash) mencapai rasio hit window penuh sebelum crash.
+// CrashTracker dipasang sebagai Observer untuk mengetahui hit setiap
+// request.
+type CrashTracker struct {
+	window    int
+	minSample int
+
+	// hasil hit window terakhir sebagai ring buffer
+	hits    []bool
+	next    int
+	filled  int
+	hitSum  int
+	current bool
+
+	crashes []crash
+	// request sejak crash terakhir
+	since int
+}
+
+// NewCrashTracker membuat tracker dengan window request; minSample
+// (1..window) adalah jumlah request minimal setelah crash sebelum rasio
+// hit dibandingkan, sehingga waktu pulih bisa lebih pendek dari window
+func NewCrashTracker(window, minSample int) *CrashTracker {
+	return &CrashTracker{window: window, minSample: minSample, hits: make([]bool, window)}
+}
+
+func (tracker *CrashTracker) Observe(event Event) {
+	if event.Kind == EventHit {
+		tracker.current = true
+	}
+}
+
+// Request dipanggil setelah setiap Get dengan timestamp trace
+func (tracker *CrashTracker) Request(time int64) {
+	if tracker.filled == tracker.window {
+		if tracker.hits[tracker.next] {
+			tracker.hitSum--
+		}
+	} else {
+		tracker.filled++
+	}
+	tracker.hits[tracker.next] = tracker.current
+	if tracker.current {
+		tracker.hitSum++
+	}
+	tracker.next = (tracker.next + 1) % tracker.window
+	tracker.current = false
+	tracker.since++
+
+	if len(tracker.crashes) == 0 {
+		return
+	}
+	last := &tracker.crashes[len(tracker.crashes)-1]
+	if last.recovery < 0 && tracker.filled >= tracker.minSample && tracker.ratio() >= last.before {
+		last.recovery = tracker.since
+		last.recoveryTime = time - last.time
+	}
+}
+
+// Crashed mencatat crash sebelum request berikutnya; window diulang agar
+// rasio hit setelah crash tidak bercampur dengan sebelum crash. Crash
+// sebelum window penuh kembali memakai rasio acuan crash sebelumnya.
+func (tracker *CrashTracker) Crashed(request int, time int64, result CrashResult) {
+	before := tracker.ratio()
+	if count := len(tracker.crashes); count > 0 && tracker.filled < tracker.window {
+		before = tracker.crashes[count-1].before
+	}
+	tracker.crashes = append(tracker.crashes, crash{
+		CrashResult: result,
+		request:     request,
+		time:        time,
+		before:      before,
+		recovery:    -1,
+	})
+	tracker.next, tracker.filled, tracker.hitSum, tracker.since = 0, 0, 0, 0
+}
+
+func (tracker *CrashTracker) ratio() float64 {
+	if tracker.filled == 0 {
+		return 0
+	}
+	return float64(tracker.hitSum) / float64(tracker.filled)
+}
+
+// crashTrackerState adalah isi checkpoint CrashTracker
+type crashTrackerState struct {
+	Hits    []bool
+	Next    int
+	Filled  int
+	HitSum  int
+	Since   int
+	Crashes []crashState
+}
+
+type crashState struct {
+	Cleared      int
+	Dirty        int
+	Request      int
+	Time         int64
+	Before       float64
+	Recovery     int
+	RecoveryTime int64
+}
+
+func (tracker *CrashTracker) Snapshot(enc *gob.Encoder) error {
+	state := crashTrackerState{
+		Hits:   tracker.hits,
+		Next:   tracker.next,
+		Filled: tracker.filled,
+		HitSum: tracker.hitSum,
+		Since:  tracker.since,
+	}
+	for _, crash := range tracker.crashes {
+		state.Crashes = append(state.Crashes, crashState{
+			Cleared:      crash.Cleared,
+			Dirty:        crash.Dirty,
+			Request:      crash.request,
+			Time:         crash.time,
+			Before:       crash.before,
+			Recovery:     crash.recovery,
+			RecoveryTime: crash.recoveryTime,
+		})
+	}
+	return enc.Encode(state)
+}
+
+func (tracker *CrashTracker) Restore(dec *gob.Decoder) error {
+	var state crashTrackerState
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	if len(state.Hits) != tracker.window {
+		return fmt.Errorf("crash window is %d, not %d", len(state.Hits), tracker.window)
+	}
+	tracker.hits, tracker.next, tracker.filled, tracker.hitSum, tracker.since = state.Hits, state.Next, state.Filled, state.HitSum, state.Since
+	tracker.crashes = tracker.crashes[:0]
+	for _, saved := range state.Crashes {
+		tracker.crashes = append(tracker.crashes, crash{
+			CrashResult:  CrashResult{Cleared: saved.Cleared, Dirty: saved.Dirty},
+			request:      saved.Request,
+			time:         saved.Time,
+			before:       saved.Before,
+			recovery:     saved.Recovery,
+			recoveryTime: saved.RecoveryTime,
+		})
+	}
+	return nil
+}
+
+func (tracker *CrashTracker) PrintToFile(file *os.File, cacheSize int) (err error) {
+	if tracker == nil {
+		return nil
+	}
+	var cleared, dirty, recovered, recoverySum int
+	result := ""
+	for i, crash := range tracker.crashes {
+		cleared += crash.Cleared
+		dirty += crash.Dirty
+		recovery := "not recovered"
+		if crash.recovery >= 0 {
+			recovered++
+			recoverySum += crash.recovery
+			recovery = fmt.Sprintf("recovered after %v requests (time %v)", crash.recovery, crash.recoveryTime)
+			if crash.recovery == tracker.minSample {
+				// sudah pulih pada sampel pertama yang dibandingkan
+				recovery = fmt.Sprintf("recovered within %v requests (min sample, time %v)", crash.recovery, crash.recoveryTime)
+			}
+		}
+		result += fmt.Sprintf("crash %v : request %v, time %v, cleared %v, dirty lost %v, hit ratio before %.4f, %v\n",
+			i+1, crash.request, crash.time, crash.Cleared, crash.Dirty, crash.before*100, recovery)
+		result += fmt.Sprintf("!CRASH|%v|%v|%v|%v|%v|%v|%v\n", cacheSize, crash.request, crash.Cleared, crash.Dirty, crash.before, crash.recovery, crash.recoveryTime)
+	}
+	meanRecovery := 0.0
+	if recovered > 0 {
+		meanRecovery = float64(recoverySum) / float64(recovered)
+	}
+	result += fmt.Sprintf(`crash count : %v
+crash cleared : %v
+crash dirty lost : %v
+crash recovered : %v
+crash mean recovery : %v
+!CRASHES|%v|%v|%v|%v|%v
+`,
+		len(tracker.crashes),
+		cleared,
+		dirty,
+		recovered,
+		meanRecovery,
+		cacheSize,
+		len(tracker.crashes),
+		cleared,
+		dirty,
+		meanRecovery,
+	)
+	_, err = file.WriteString(result)
+	return err
+}
+
+// CrashSchedule menentukan kapan crash terjadi: setiap every request
+// dan/atau saat timestamp trace mencapai salah satu times (terurut naik)
+type CrashSchedule struct {
+	every int
+	times []int64
+	next  int
+}
+
+func NewCrashSchedule(every int, times []int64) *CrashSchedule {
+	return &CrashSchedule{every: every, times: times}
+}
+
+// Due dipanggil sebelum setiap request; completed adalah jumlah request
+// yang sudah diproses dan time timestamp request berikutnya. Beberapa
+// timestamp yang terlewati sekaligus menjadi satu crash.
+func (schedule *CrashSchedule) Due(completed int, time int64) (due bool) {
+	if schedule == nil {
+		return false
+	}
+	if schedule.every > 0 && completed > 0 && completed%schedule.every == 0 {
+		due = true
+	}
+	for schedule.next < len(schedule.times) && schedule.times[schedule.next] <= time {
+		schedule.next++
+		due = true
+	}
+	return due
+}
+
+func (schedule *CrashSchedule) Snapshot(enc *gob.Encoder) error {
+	return enc.Encode(schedule.next)
+}
+
+func (schedule *CrashSchedule) Restore(dec *gob.Decoder) error {
+	return dec.Decode(&schedule.next)
+}
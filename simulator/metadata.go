@@ -0,0 +1,112 @@
+package simulator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// Perkiraan ukuran struktur yang dipakai bersama oleh banyak simulator
+// (amd64), dipakai untuk menghitung byte per entri metadata.
+const (
+	MapEntryBytes        = 24 // satu entri map[int]pointer termasuk overhead bucket
+	InterfaceMapBytes    = 40 // satu entri map[interface{}]pointer
+	ListElementBytes     = 48 // container/list.Element
+	OrderedMapEntryBytes = 48 + InterfaceMapBytes
+	LLRBNodeBytes        = 40
+)
+
+// Metadata adalah jumlah entri yang dilacak simulator pada satu waktu.
+// Ghost adalah entri yang dilacak tetapi datanya tidak ada di cache,
+// FixedBytes untuk struktur berukuran tetap seperti sketch atau bloom filter.
+type Metadata struct {
+	Tracked       int
+	Ghost         int
+	BytesPerEntry int
+	FixedBytes    int
+}
+
+type MetadataReporter interface {
+	Metadata() Metadata
+}
+
+// MetadataTracker mencatat nilai puncak Metadata selama replay
+type MetadataTracker struct {
+	reporter MetadataReporter
+
+	peakTracked   int
+	peakGhost     int
+	bytesPerEntry int
+	fixedBytes    int
+}
+
+// NewMetadataTracker mengembalikan nil jika simulator tidak melaporkan
+// metadata; semua method aman dipanggil pada tracker nil
+func NewMetadataTracker(sim Simulator) *MetadataTracker {
+	reporter, ok := sim.(MetadataReporter)
+	if !ok {
+		return nil
+	}
+	return &MetadataTracker{reporter: reporter}
+}
+
+func (tracker *MetadataTracker) Observe() {
+	if tracker == nil {
+		return
+	}
+	metadata := tracker.reporter.Metadata()
+	if metadata.Tracked > tracker.peakTracked {
+		tracker.peakTracked = metadata.Tracked
+	}
+	if metadata.Ghost > tracker.peakGhost {
+		tracker.peakGhost = metadata.Ghost
+	}
+	tracker.bytesPerEntry = metadata.BytesPerEntry
+	tracker.fixedBytes = metadata.FixedBytes
+}
+
+// Snapshot dan Restore menyimpan nilai puncak untuk checkpoint
+func (tracker *MetadataTracker) Snapshot(enc *gob.Encoder) error {
+	return enc.Encode([4]int{tracker.peakTracked, tracker.peakGhost, tracker.bytesPerEntry, tracker.fixedBytes})
+}
+
+func (tracker *MetadataTracker) Restore(dec *gob.Decoder) error {
+	var state [4]int
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	tracker.peakTracked, tracker.peakGhost, tracker.bytesPerEntry, tracker.fixedBytes = state[0], state[1], state[2], state[3]
+	return nil
+}
+
+func (tracker *MetadataTracker) PeakBytes() int {
+	if tracker == nil {
+		return 0
+	}
+	return tracker.peakTracked*tracker.bytesPerEntry + tracker.fixedBytes
+}
+
+func (tracker *MetadataTracker) PrintToFile(file *os.File, cacheSize int) (err error) {
+	if tracker == nil {
+		return nil
+	}
+	result := fmt.Sprintf(`metadata tracked peak : %v
+metadata ghost peak : %v
+metadata bytes/entry : %v
+metadata fixed bytes : %v
+metadata bytes peak : %v
+!META|%v|%v|%v|%v
+`,
+		tracker.peakTracked,
+		tracker.peakGhost,
+		tracker.bytesPerEntry,
+		tracker.fixedBytes,
+		tracker.PeakBytes(),
+		cacheSize,
+		tracker.peakTracked,
+		tracker.peakGhost,
+		tracker.PeakBytes(),
+	)
+	_, err = file.WriteString(result)
+	return err
+}
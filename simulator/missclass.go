@@ -0,0 +1,204 @@
+package simulator
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// MissReporter diimplementasikan simulator yang bisa diklasifikasikan
+// miss-nya; Capacity adalah jumlah blok yang bisa disimpan cache (untuk
+// WEC RAM dan SSD), dipakai sebagai ukuran cache OPT pembanding
+type MissReporter interface {
+	Misses() int
+	Capacity() int
+}
+
+// MissClassifier membagi setiap miss simulator menjadi:
+//
+//   - cold: referensi pertama ke blok tersebut di trace
+//   - capacity: cache OPT (Belady) dengan ukuran yang sama juga miss
+//   - policy: cache OPT hit, miss disebabkan kebijakan algoritma
+//
+// OPT dimulai dari cache kosong dan memperlakukan read dan write sama.
+type MissClassifier struct {
+	reporter MissReporter
+	capacity int
+
+	// cold dan optMiss per request trace
+	cold    []bool
+	optMiss []bool
+
+	misses   int
+	coldMiss int
+	capMiss  int
+	policy   int
+	optCount int
+}
+
+// NewMissClassifier menjalankan OPT atas seluruh trace; mengembalikan nil
+// jika simulator tidak melaporkan miss, semua method aman dipanggil pada
+// classifier nil
+func NewMissClassifier(sim Simulator, traces []Trace) *MissClassifier {
+	reporter, ok := sim.(MissReporter)
+	if !ok {
+		return nil
+	}
+	classifier := &MissClassifier{
+		reporter: reporter,
+		capacity: reporter.Capacity(),
+		cold:     make([]bool, len(traces)),
+		misses:   reporter.Misses(),
+	}
+	seen := make(map[int]struct{})
+	for i, trace := range traces {
+		if _, ok := seen[trace.Addr]; !ok {
+			seen[trace.Addr] = struct{}{}
+			classifier.cold[i] = true
+		}
+	}
+	classifier.optMiss = optMisses(traces, classifier.capacity)
+	for _, miss := range classifier.optMiss {
+		if miss {
+			classifier.optCount++
+		}
+	}
+	return classifier
+}
+
+// Request dipanggil setelah Get request ke-index; miss dikenali dari
+// bertambahnya Misses
+func (classifier *MissClassifier) Request(index int) {
+	if classifier == nil {
+		return
+	}
+	misses := classifier.reporter.Misses()
+	missed := misses - classifier.misses
+	classifier.misses = misses
+	if missed <= 0 {
+		return
+	}
+	switch {
+	case classifier.cold[index]:
+		classifier.coldMiss += missed
+	case classifier.optMiss[index]:
+		classifier.capMiss += missed
+	default:
+		classifier.policy += missed
+	}
+}
+
+// Snapshot dan Restore menyimpan jumlah miss per kelas untuk checkpoint;
+// hasil OPT dihitung ulang dari trace
+func (classifier *MissClassifier) Snapshot(enc *gob.Encoder) error {
+	return enc.Encode([3]int{classifier.coldMiss, classifier.capMiss, classifier.policy})
+}
+
+func (classifier *MissClassifier) Restore(dec *gob.Decoder) error {
+	var state [3]int
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	classifier.coldMiss, classifier.capMiss, classifier.policy = state[0], state[1], state[2]
+	classifier.misses = classifier.reporter.Misses()
+	return nil
+}
+
+func (classifier *MissClassifier) PrintToFile(file *os.File, cacheSize int) (err error) {
+	if classifier == nil {
+		return nil
+	}
+	result := fmt.Sprintf(`miss cold : %v
+miss capacity : %v
+miss policy : %v
+opt capacity : %v
+opt miss : %v
+!MISS|%v|%v|%v|%v|%v
+`,
+		classifier.coldMiss,
+		classifier.capMiss,
+		classifier.policy,
+		classifier.capacity,
+		classifier.optCount,
+		cacheSize,
+		classifier.coldMiss,
+		classifier.capMiss,
+		classifier.policy,
+		classifier.optCount,
+	)
+	_, err = file.WriteString(result)
+	return err
+}
+
+// optMisses mensimulasikan OPT (Belady MIN): blok yang dipakai lagi paling
+// lama diganti, dan blok baru tidak dimasukkan bila blok itu sendiri yang
+// dipakai lagi paling lama
+func optMisses(traces []Trace, capacity int) []bool {
+	never := len(traces)
+	next := make([]int, len(traces))
+	last := make(map[int]int)
+	for i := len(traces) - 1; i >= 0; i-- {
+		next[i] = never
+		if j, ok := last[traces[i].Addr]; ok {
+			next[i] = j
+		}
+		last[traces[i].Addr] = i
+	}
+
+	missed := make([]bool, len(traces))
+	// nextUse blok di cache; entri heap yang nextUse-nya sudah berubah
+	// diabaikan saat diambil
+	cached := make(map[int]int, capacity)
+	queue := &optQueue{}
+	for i, trace := range traces {
+		if _, ok := cached[trace.Addr]; !ok {
+			missed[i] = true
+			if capacity <= 0 {
+				continue
+			}
+			if len(cached) >= capacity {
+				victim := queue.top(cached)
+				if cached[victim.addr] <= next[i] {
+					continue
+				}
+				heap.Pop(queue)
+				delete(cached, victim.addr)
+			}
+		}
+		cached[trace.Addr] = next[i]
+		heap.Push(queue, optEntry{addr: trace.Addr, next: next[i]})
+	}
+	return missed
+}
+
+type optEntry struct {
+	addr int
+	next int
+}
+
+// optQueue adalah max-heap berdasarkan next
+type optQueue []optEntry
+
+func (queue optQueue) Len() int           { return len(queue) }
+func (queue optQueue) Less(i, j int) bool { return queue[i].next > queue[j].next }
+func (queue optQueue) Swap(i, j int)      { queue[i], queue[j] = queue[j], queue[i] }
+func (queue *optQueue) Push(x any)        { *queue = append(*queue, x.(optEntry)) }
+func (queue *optQueue) Pop() any {
+	old := *queue
+	entry := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return entry
+}
+
+// top membuang entri usang lalu mengembalikan blok yang dipakai lagi
+// paling lama
+func (queue *optQueue) top(cached map[int]int) optEntry {
+	for {
+		entry := (*queue)[0]
+		if next, ok := cached[entry.addr]; ok && next == entry.next {
+			return entry
+		}
+		heap.Pop(queue)
+	}
+}
@@ -0,0 +1,182 @@
+package simulator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// EventKind adalah jenis kejadian yang dilaporkan simulator ke Observer
+type EventKind uint8
+
+const (
+	EventHit EventKind = iota + 1
+	EventMiss
+	// EventInsert: blok masuk ke cache (tier Tier)
+	EventInsert
+	// EventEvict: blok keluar dari cache, Tier adalah tier asalnya
+	EventEvict
+	// EventPromote/EventDemote: blok pindah dari From ke tier yang lebih
+	// cepat/lambat di dalam cache
+	EventPromote
+	EventDemote
+	EventSSDWrite
+)
+
+var eventKinds = []EventKind{EventHit, EventMiss, EventInsert, EventEvict, EventPromote, EventDemote, EventSSDWrite}
+
+func (kind EventKind) String() string {
+	switch kind {
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	case EventInsert:
+		return "insert"
+	case EventEvict:
+		return "evict"
+	case EventPromote:
+		return "promote"
+	case EventDemote:
+		return "demote"
+	case EventSSDWrite:
+		return "ssd-write"
+	}
+	return "unknown"
+}
+
+// Tier adalah lokasi blok saat kejadian; cache satu tier (LRU, LFU)
+// memakai TierSSD, LIRS memakai TierLIR/TierHIR untuk partisinya dengan
+// HIR ke LIR sebagai promote
+type Tier uint8
+
+const (
+	TierNone Tier = iota
+	TierRAM
+	TierSSD
+	TierHDD
+	TierLIR
+	TierHIR
+)
+
+var tiers = []Tier{TierNone, TierRAM, TierSSD, TierHDD, TierLIR, TierHIR}
+
+func (tier Tier) String() string {
+	switch tier {
+	case TierRAM:
+		return "RAM"
+	case TierSSD:
+		return "SSD"
+	case TierHDD:
+		return "HDD"
+	case TierLIR:
+		return "LIR"
+	case TierHIR:
+		return "HIR"
+	}
+	return "-"
+}
+
+// Event adalah satu kejadian; Request adalah nomor request (mulai 1)
+// yang memicunya
+type Event struct {
+	Kind    EventKind
+	Addr    int
+	Tier    Tier
+	From    Tier
+	Request int
+}
+
+// Observer menerima kejadian secara sinkron di dalam Get
+type Observer interface {
+	Observe(Event)
+}
+
+// ObserverFunc memakai fungsi biasa sebagai Observer
+type ObserverFunc func(Event)
+
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+// Observable diimplementasikan simulator yang melaporkan kejadian
+type Observable interface {
+	SetObserver(Observer)
+}
+
+// Emitter disematkan ke simulator untuk mengimplementasikan Observable;
+// tanpa observer setiap Emit hanya berupa satu pemeriksaan nil
+type Emitter struct {
+	observer Observer
+}
+
+func (emitter *Emitter) SetObserver(observer Observer) {
+	emitter.observer = observer
+}
+
+func (emitter *Emitter) Emit(kind EventKind, addr int, tier Tier, request int) {
+	if emitter.observer != nil {
+		emitter.observer.Observe(Event{Kind: kind, Addr: addr, Tier: tier, Request: request})
+	}
+}
+
+// EmitMove melaporkan perpindahan blok dari tier from ke tier to
+func (emitter *Emitter) EmitMove(kind EventKind, addr int, from, to Tier, request int) {
+	if emitter.observer != nil {
+		emitter.observer.Observe(Event{Kind: kind, Addr: addr, Tier: to, From: from, Request: request})
+	}
+}
+
+// EventCounter menghitung kejadian per jenis dan tier
+type EventCounter struct {
+	counts [EventSSDWrite + 1][TierHIR + 1]int
+}
+
+func (counter *EventCounter) Observe(event Event) {
+	counter.counts[event.Kind][event.Tier]++
+}
+
+func (counter *EventCounter) Count(kind EventKind, tier Tier) int {
+	return counter.counts[kind][tier]
+}
+
+func (counter *EventCounter) Snapshot(enc *gob.Encoder) error {
+	return enc.Encode(counter.counts)
+}
+
+func (counter *EventCounter) Restore(dec *gob.Decoder) error {
+	return dec.Decode(&counter.counts)
+}
+
+func (counter *EventCounter) PrintToFile(file *os.File, cacheSize int) (err error) {
+	if counter == nil {
+		return nil
+	}
+	result := ""
+	for _, kind := range eventKinds {
+		for _, tier := range tiers {
+			if count := counter.counts[kind][tier]; count > 0 {
+				result += fmt.Sprintf("event %v %v : %v\n", kind, tier, count)
+				result += fmt.Sprintf("!EVENT|%v|%v|%v|%v\n", cacheSize, kind, tier, count)
+			}
+		}
+	}
+	_, err = file.WriteString(result)
+	return err
+}
+
+func (event Event) String() string {
+	if event.Kind == EventPromote || event.Kind == EventDemote {
+		return fmt.Sprintf("%v %v->%v %v", event.Kind, event.From, event.Tier, event.Addr)
+	}
+	return fmt.Sprintf("%v %v %v", event.Kind, event.Tier, event.Addr)
+}
+
+// Observers meneruskan setiap event ke seluruh observer secara berurutan
+type Observers []Observer
+
+func (observers Observers) Observe(event Event) {
+	for _, observer := range observers {
+		observer.Observe(event)
+	}
+}
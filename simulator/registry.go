@@ -0,0 +1,269 @@
+package simulator
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamKind adalah tipe nilai parameter algoritma
+type ParamKind int
+
+const (
+	ParamInt ParamKind = iota
+	ParamInt64
+	ParamFloat
+	ParamString
+)
+
+func (kind ParamKind) String() string {
+	switch kind {
+	case ParamInt, ParamInt64:
+		return "int"
+	case ParamFloat:
+		return "float"
+	case ParamString:
+		return "string"
+	}
+	return "unknown"
+}
+
+// holds memeriksa apakah value bertipe Go yang sesuai dengan kind
+func (kind ParamKind) holds(value any) bool {
+	switch value.(type) {
+	case int:
+		return kind == ParamInt
+	case int64:
+		return kind == ParamInt64
+	case float64:
+		return kind == ParamFloat
+	case string:
+		return kind == ParamString
+	}
+	return false
+}
+
+// Param mendeskripsikan satu parameter algoritma; Name sekaligus menjadi
+// nama flag CLI sehingga harus unik kecuali dipakai bersama dengan tipe
+// dan default yang sama (misalnya LeCaR dan CACHEUS)
+type Param struct {
+	Name    string
+	Kind    ParamKind
+	Default any
+	Usage   string
+}
+
+// Parse mengubah teks menjadi nilai sesuai Kind
+func (param Param) Parse(s string) (value any, err error) {
+	switch param.Kind {
+	case ParamInt:
+		value, err = strconv.Atoi(s)
+	case ParamInt64:
+		value, err = strconv.ParseInt(s, 10, 64)
+	case ParamFloat:
+		value, err = strconv.ParseFloat(s, 64)
+	case ParamString:
+		value = s
+	default:
+		err = fmt.Errorf("unknown kind %v", param.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s: %w", param.Name, err)
+	}
+	return value, nil
+}
+
+// Params berisi nilai parameter yang sudah bertipe, dibaca oleh factory
+type Params map[string]any
+
+func (params Params) Int(name string) int {
+	return get[int](params, name)
+}
+
+func (params Params) Int64(name string) int64 {
+	return get[int64](params, name)
+}
+
+func (params Params) Float(name string) float64 {
+	return get[float64](params, name)
+}
+
+func (params Params) String(name string) string {
+	return get[string](params, name)
+}
+
+// get panic bila parameter tidak terdaftar atau tipenya salah, keduanya
+// kesalahan pada pendaftaran algoritma
+func get[T any](params Params, name string) T {
+	value, ok := params[name].(T)
+	if !ok {
+		panic(fmt.Sprintf("simulator: parameter %q is not a %T", name, value))
+	}
+	return value
+}
+
+// Algorithm adalah satu algoritma yang terdaftar di registry
+type Algorithm struct {
+	// Name unik dan huruf kecil, dipakai oleh -algo
+	Name        string
+	Description string
+	Params      []Param
+	New         func(cacheSize int, params Params) (Simulator, error)
+	// Admission bernilai true bila simulator bisa dibungkus admission policy
+	Admission bool
+	// OutputTags adalah parameter yang dicantumkan di nama file keluaran
+	OutputTags []string
+}
+
+// Defaults mengembalikan nilai default seluruh parameter
+func (algorithm Algorithm) Defaults() Params {
+	params := make(Params, len(algorithm.Params))
+	for _, param := range algorithm.Params {
+		params[param.Name] = param.Default
+	}
+	return params
+}
+
+// ParseParams membaca nilai parameter dalam bentuk teks, parameter yang
+// tidak disebut memakai default
+func (algorithm Algorithm) ParseParams(values map[string]string) (Params, error) {
+	params := algorithm.Defaults()
+	for name, text := range values {
+		param, ok := algorithm.param(name)
+		if !ok {
+			return nil, fmt.Errorf("%s has no parameter %q", algorithm.Name, name)
+		}
+		value, err := param.Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+func (algorithm Algorithm) param(name string) (Param, bool) {
+	for _, param := range algorithm.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return Param{}, false
+}
+
+var registry = map[string]Algorithm{}
+
+// Register dipanggil dari init paket algoritma; nama ganda atau parameter
+// bersama yang berbeda tipe/default menyebabkan panic
+func Register(algorithm Algorithm) {
+	algorithm.Name = strings.ToLower(algorithm.Name)
+	if _, ok := registry[algorithm.Name]; ok {
+		panic("simulator: algorithm registered twice: " + algorithm.Name)
+	}
+	for _, param := range algorithm.Params {
+		if !param.Kind.holds(param.Default) {
+			panic(fmt.Sprintf("simulator: %s: default of %s is %T, not %v", algorithm.Name, param.Name, param.Default, param.Kind))
+		}
+		for _, other := range registry {
+			if shared, ok := other.param(param.Name); ok && (shared.Kind != param.Kind || shared.Default != param.Default) {
+				panic(fmt.Sprintf("simulator: parameter %s of %s conflicts with %s", param.Name, algorithm.Name, other.Name))
+			}
+		}
+	}
+	registry[algorithm.Name] = algorithm
+}
+
+// Lookup mencari algoritma tanpa membedakan huruf besar/kecil
+func Lookup(name string) (Algorithm, bool) {
+	algorithm, ok := registry[strings.ToLower(name)]
+	return algorithm, ok
+}
+
+// Algorithms mengembalikan seluruh algoritma terurut nama
+func Algorithms() []Algorithm {
+	algorithms := make([]Algorithm, 0, len(registry))
+	for _, algorithm := range registry {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Slice(algorithms, func(i, j int) bool {
+		return algorithms[i].Name < algorithms[j].Name
+	})
+	return algorithms
+}
+
+// Names adalah daftar nama algoritma dalam huruf besar, untuk teks bantuan
+func Names() []string {
+	var names []string
+	for _, algorithm := range Algorithms() {
+		names = append(names, strings.ToUpper(algorithm.Name))
+	}
+	return names
+}
+
+func defaultText(param Param) string {
+	if param.Kind == ParamString {
+		return strconv.Quote(fmt.Sprint(param.Default))
+	}
+	return fmt.Sprint(param.Default)
+}
+
+// FlagParams menyimpan pointer nilai flag setiap parameter di registry
+type FlagParams map[string]any
+
+// BindFlags mendaftarkan setiap parameter di registry sebagai flag bertipe
+func BindFlags(fs *flag.FlagSet) FlagParams {
+	values := FlagParams{}
+	for _, algorithm := range Algorithms() {
+		for _, param := range algorithm.Params {
+			if _, ok := values[param.Name]; ok {
+				continue
+			}
+			switch param.Kind {
+			case ParamInt:
+				values[param.Name] = fs.Int(param.Name, param.Default.(int), param.Usage)
+			case ParamInt64:
+				values[param.Name] = fs.Int64(param.Name, param.Default.(int64), param.Usage)
+			case ParamFloat:
+				values[param.Name] = fs.Float64(param.Name, param.Default.(float64), param.Usage)
+			case ParamString:
+				values[param.Name] = fs.String(param.Name, param.Default.(string), param.Usage)
+			}
+		}
+	}
+	return values
+}
+
+// Params mengambil nilai flag untuk parameter algorithm
+func (values FlagParams) Params(algorithm Algorithm) Params {
+	params := make(Params, len(algorithm.Params))
+	for _, param := range algorithm.Params {
+		switch value := values[param.Name].(type) {
+		case *int:
+			params[param.Name] = *value
+		case *int64:
+			params[param.Name] = *value
+		case *float64:
+			params[param.Name] = *value
+		case *string:
+			params[param.Name] = *value
+		}
+	}
+	return params
+}
+
+// PrintAlgorithms menulis setiap algoritma beserta parameternya
+func PrintAlgorithms(w io.Writer) {
+	for _, algorithm := range Algorithms() {
+		fmt.Fprintf(w, "%s\t%s\n", strings.ToUpper(algorithm.Name), algorithm.Description)
+		for _, param := range algorithm.Params {
+			usage := strings.ReplaceAll(param.Usage, "\n", " ")
+			fmt.Fprintf(w, "  -%s %s (default %s)\n    \t%s\n", param.Name, param.Kind, defaultText(param), usage)
+		}
+		if algorithm.Admission {
+			fmt.Fprintf(w, "  mendukung -admission\n")
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package simulator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/bits"
+	"os"
+)
+
+// Histogram menghitung nilai dalam bucket pangkat dua: bucket 0 berisi
+// nilai 0, bucket k berisi [2^(k-1), 2^k)
+type Histogram struct {
+	Buckets []int
+	Count   int
+	Sum     int64
+}
+
+func (histogram *Histogram) Add(value int64) {
+	if value < 0 {
+		value = 0
+	}
+	bucket := bits.Len64(uint64(value))
+	for len(histogram.Buckets) <= bucket {
+		histogram.Buckets = append(histogram.Buckets, 0)
+	}
+	histogram.Buckets[bucket]++
+	histogram.Count++
+	histogram.Sum += value
+}
+
+func (histogram *Histogram) Mean() float64 {
+	if histogram.Count == 0 {
+		return 0
+	}
+	return float64(histogram.Sum) / float64(histogram.Count)
+}
+
+// format menulis setiap bucket yang tidak kosong sebagai "<label> lo-hi : n"
+// dan "!<tag>|<fields>|lo|n"
+func (histogram *Histogram) format(label, tag string, fields ...any) (result string) {
+	prefix := tag
+	for _, field := range fields {
+		prefix += fmt.Sprintf("|%v", field)
+	}
+	for bucket, count := range histogram.Buckets {
+		if count == 0 {
+			continue
+		}
+		var low, high int64
+		if bucket > 0 {
+			low, high = 1<<(bucket-1), 1<<bucket-1
+		}
+		result += fmt.Sprintf("%v %v-%v : %v\n", label, low, high, count)
+		result += fmt.Sprintf("!%v|%v|%v\n", prefix, low, count)
+	}
+	return result
+}
+
+// DwellReporter diimplementasikan simulator yang memiliki antrean dengan
+// batas umur (SPQueue WEC); QueueDwell mengembalikan lama tinggal blok di
+// antrean tersebut per cara keluarnya
+type DwellReporter interface {
+	QueueDwell() QueueDwell
+}
+
+// QueueDwell adalah lama tinggal di antrean Queue; Age memakai satuan yang
+// dibandingkan dengan Limit, LimitType adalah cara Limit dihitung
+type QueueDwell struct {
+	Queue     string
+	Limit     int
+	LimitType string
+	Outcomes  []DwellOutcome
+}
+
+type DwellOutcome struct {
+	Name     string
+	Requests Histogram
+	Age      Histogram
+}
+
+// ResidencyTracker mencatat lama blok berada di setiap tier sampai dievict
+// (dalam request dan timestamp trace) dan menghitung eviksi prematur, yaitu
+// blok yang direferensikan lagi paling lama window request setelah
+// dievict. ResidencyTracker dipasang sebagai Observer; pindah tier
+// (promote/demote) memulai lama tinggal di tier baru.
+type ResidencyTracker struct {
+	window  int
+	timed   bool
+	dweller DwellReporter
+
+	request int
+	time    int64
+
+	resident map[int]residence
+	requests [TierHIR + 1]Histogram
+	times    [TierHIR + 1]Histogram
+
+	// request eviksi terakhir per blok, evictions mengurutkannya agar
+	// eviksi yang lebih lama dari window bisa dibuang
+	evicted   map[int]int
+	evictions []eviction
+	evictSum  int
+	premature int
+}
+
+type residence struct {
+	Tier    Tier
+	Request int
+	Time    int64
+}
+
+type eviction struct {
+	Addr    int
+	Request int
+}
+
+// NewResidencyTracker membuat tracker untuk sim; timed menentukan apakah
+// histogram timestamp trace ditulis
+func NewResidencyTracker(sim Simulator, window int, timed bool) *ResidencyTracker {
+	tracker := &ResidencyTracker{
+		window:   window,
+		timed:    timed,
+		resident: map[int]residence{},
+		evicted:  map[int]int{},
+	}
+	tracker.dweller, _ = sim.(DwellReporter)
+	return tracker
+}
+
+// Begin dipanggil sebelum Get request ke-index
+func (tracker *ResidencyTracker) Begin(index int, trace Trace) {
+	tracker.request, tracker.time = index, trace.Time
+	for len(tracker.evictions) > 0 && index-tracker.evictions[0].Request > tracker.window {
+		old := tracker.evictions[0]
+		if tracker.evicted[old.Addr] == old.Request {
+			delete(tracker.evicted, old.Addr)
+		}
+		tracker.evictions = tracker.evictions[1:]
+	}
+	if _, ok := tracker.evicted[trace.Addr]; ok {
+		tracker.premature++
+		delete(tracker.evicted, trace.Addr)
+	}
+}
+
+func (tracker *ResidencyTracker) Observe(event Event) {
+	switch event.Kind {
+	case EventInsert, EventPromote, EventDemote:
+		tracker.resident[event.Addr] = residence{Tier: event.Tier, Request: tracker.request, Time: tracker.time}
+	case EventEvict:
+		// blok yang masuk sebelum tracker dipasang tidak memiliki awal
+		if entered, ok := tracker.resident[event.Addr]; ok && entered.Tier == event.Tier {
+			tracker.requests[event.Tier].Add(int64(tracker.request - entered.Request))
+			tracker.times[event.Tier].Add(tracker.time - entered.Time)
+		}
+		delete(tracker.resident, event.Addr)
+		tracker.evicted[event.Addr] = tracker.request
+		tracker.evictions = append(tracker.evictions, eviction{Addr: event.Addr, Request: tracker.request})
+		tracker.evictSum++
+	}
+}
+
+// residencyState adalah isi checkpoint ResidencyTracker
+type residencyState struct {
+	Request   int
+	Time      int64
+	Resident  map[int]residence
+	Requests  [TierHIR + 1]Histogram
+	Times     [TierHIR + 1]Histogram
+	Evicted   map[int]int
+	Evictions []eviction
+	EvictSum  int
+	Premature int
+}
+
+func (tracker *ResidencyTracker) Snapshot(enc *gob.Encoder) error {
+	return enc.Encode(residencyState{
+		Request:   tracker.request,
+		Time:      tracker.time,
+		Resident:  tracker.resident,
+		Requests:  tracker.requests,
+		Times:     tracker.times,
+		Evicted:   tracker.evicted,
+		Evictions: tracker.evictions,
+		EvictSum:  tracker.evictSum,
+		Premature: tracker.premature,
+	})
+}
+
+func (tracker *ResidencyTracker) Restore(dec *gob.Decoder) error {
+	var state residencyState
+	if err := dec.Decode(&state); err != nil {
+		return err
+	}
+	if state.Resident == nil {
+		state.Resident = map[int]residence{}
+	}
+	if state.Evicted == nil {
+		state.Evicted = map[int]int{}
+	}
+	tracker.request, tracker.time = state.Request, state.Time
+	tracker.resident, tracker.requests, tracker.times = state.Resident, state.Requests, state.Times
+	tracker.evicted, tracker.evictions = state.Evicted, state.Evictions
+	tracker.evictSum, tracker.premature = state.EvictSum, state.Premature
+	return nil
+}
+
+func (tracker *ResidencyTracker) PrintToFile(file *os.File, cacheSize int) (err error) {
+	if tracker == nil {
+		return nil
+	}
+	result := ""
+	for _, tier := range tiers {
+		requests, times := &tracker.requests[tier], &tracker.times[tier]
+		if requests.Count == 0 {
+			continue
+		}
+		result += fmt.Sprintf("residency %v evicted : %v, mean requests %.2f, mean time %.2f\n", tier, requests.Count, requests.Mean(), times.Mean())
+		result += fmt.Sprintf("!RESIDENCIES|%v|%v|%v|%v|%v\n", cacheSize, tier, requests.Count, requests.Mean(), times.Mean())
+		result += requests.format(fmt.Sprintf("residency %v requests", tier), "RESIDENCY", cacheSize, tier, "requests")
+		if tracker.timed {
+			result += times.format(fmt.Sprintf("residency %v time", tier), "RESIDENCY", cacheSize, tier, "time")
+		}
+	}
+	result += fmt.Sprintf("premature evictions : %v of %v (window %v)\n", tracker.premature, tracker.evictSum, tracker.window)
+	result += fmt.Sprintf("!PREMATURE|%v|%v|%v|%v\n", cacheSize, tracker.window, tracker.premature, tracker.evictSum)
+
+	if tracker.dweller != nil {
+		dwell := tracker.dweller.QueueDwell()
+		result += fmt.Sprintf("dwell %v limit : %v (%v)\n", dwell.Queue, dwell.Limit, dwell.LimitType)
+		for _, outcome := range dwell.Outcomes {
+			label := fmt.Sprintf("dwell %v %v", dwell.Queue, outcome.Name)
+			result += fmt.Sprintf("%v : %v, mean requests %.2f, mean age %.2f\n", label, outcome.Requests.Count, outcome.Requests.Mean(), outcome.Age.Mean())
+			result += fmt.Sprintf("!DWELLS|%v|%v|%v|%v|%v|%v|%v|%v\n", cacheSize, dwell.Queue, dwell.LimitType, dwell.Limit, outcome.Name, outcome.Requests.Count, outcome.Requests.Mean(), outcome.Age.Mean())
+			result += outcome.Requests.format(label+" requests", "DWELL", cacheSize, dwell.Queue, dwell.LimitType, outcome.Name, "requests")
+			result += outcome.Age.format(label+" age", "DWELL", cacheSize, dwell.Queue, dwell.LimitType, outcome.Name, "age")
+		}
+	}
+	_, err = file.WriteString(result)
+	return err
+}
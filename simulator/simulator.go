@@ -10,7 +10,11 @@ type Simulator interface {
 	PrintToFile(file *os.File, start time.Time) error
 }
 
+// Trace adalah satu request; Time dan Size bernilai 0 jika trace tidak
+// menyertakan timestamp atau ukuran request
 type Trace struct {
 	Addr int
 	Op   string
+	Time int64
+	Size int
 }
@@ -0,0 +1,190 @@
+package simulator
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Snapshotter diimplementasikan simulator yang bisa menyimpan dan
+// memulihkan seluruh state-nya (isi cache, urutan dan counter), sehingga
+// replay bisa dilanjutkan dari state tersebut. Parameter algoritma tidak
+// ikut disimpan, Restore dipanggil pada simulator baru dengan parameter
+// yang sama.
+type Snapshotter interface {
+	Snapshot(enc *gob.Encoder) error
+	Restore(dec *gob.Decoder) error
+}
+
+// Format:
+//
+//	magic "WECSNAP" + versi (1 byte) | SnapshotHeader (gob) | state simulator (gob)
+const (
+	snapshotMagic   = "WECSNAP"
+	snapshotVersion = 1
+)
+
+// SnapshotHeader mengenali run yang menghasilkan snapshot; snapshot hanya
+// bisa dimuat oleh run dengan header yang sama
+type SnapshotHeader struct {
+	Algorithm string
+	CacheSize int
+	Params    map[string]string
+}
+
+func NewSnapshotHeader(algorithm Algorithm, cacheSize int, params Params) SnapshotHeader {
+	header := SnapshotHeader{Algorithm: algorithm.Name, CacheSize: cacheSize, Params: map[string]string{}}
+	for name, value := range params {
+		header.Params[name] = fmt.Sprint(value)
+	}
+	return header
+}
+
+func (header SnapshotHeader) check(saved SnapshotHeader) error {
+	if saved.Algorithm != header.Algorithm {
+		return fmt.Errorf("saved by %s, not %s", saved.Algorithm, header.Algorithm)
+	}
+	if saved.CacheSize != header.CacheSize {
+		return fmt.Errorf("saved with cache size %d, not %d", saved.CacheSize, header.CacheSize)
+	}
+	names := make([]string, 0, len(header.Params))
+	for name := range header.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if saved.Params[name] != header.Params[name] {
+			return fmt.Errorf("saved with %s=%s, not %s", name, saved.Params[name], header.Params[name])
+		}
+	}
+	return nil
+}
+
+// SnapshotNames adalah daftar algoritma (huruf besar) yang simulatornya
+// mengimplementasikan Snapshotter, untuk teks bantuan
+func SnapshotNames() []string {
+	var names []string
+	for _, algorithm := range Algorithms() {
+		if sim, err := algorithm.New(snapshotProbeSize, algorithm.Defaults()); err == nil {
+			if _, ok := sim.(Snapshotter); ok {
+				names = append(names, strings.ToUpper(algorithm.Name))
+			}
+		}
+	}
+	return names
+}
+
+// snapshotProbeSize adalah ukuran cache simulator contoh SnapshotNames
+const snapshotProbeSize = 100
+
+// SaveSnapshot menulis state sim ke path
+func SaveSnapshot(path string, header SnapshotHeader, sim Simulator) error {
+	if _, ok := sim.(Snapshotter); !ok {
+		return fmt.Errorf("snapshot: %T does not support snapshots", sim)
+	}
+	return WriteAtomic(path, func(w io.Writer) error {
+		if _, err := w.Write(append([]byte(snapshotMagic), snapshotVersion)); err != nil {
+			return err
+		}
+		return EncodeSnapshot(gob.NewEncoder(w), header, sim)
+	})
+}
+
+// EncodeSnapshot menulis header dan state sim ke enc, dipakai juga oleh
+// checkpoint yang menyimpan state lain di aliran yang sama
+func EncodeSnapshot(enc *gob.Encoder, header SnapshotHeader, sim Simulator) error {
+	snapshotter, ok := sim.(Snapshotter)
+	if !ok {
+		return fmt.Errorf("snapshot: %T does not support snapshots", sim)
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := snapshotter.Snapshot(enc); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	return nil
+}
+
+// WriteAtomic menulis path lewat file sementara di direktori yang sama
+// lalu mengganti namanya, sehingga isi lama tetap utuh bila penulisan gagal
+func WriteAtomic(path string, write func(w io.Writer) error) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	if err = file.Chmod(0o644); err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if err = write(writer); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// LoadSnapshot memulihkan state sim dari path setelah memeriksa header
+func LoadSnapshot(path string, header SnapshotHeader, sim Simulator) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic := make([]byte, len(snapshotMagic)+1)
+	if _, err = io.ReadFull(reader, magic); err != nil || string(magic[:len(snapshotMagic)]) != snapshotMagic {
+		return fmt.Errorf("snapshot: %s: not a snapshot", path)
+	}
+	if magic[len(snapshotMagic)] != snapshotVersion {
+		return fmt.Errorf("snapshot: %s: unsupported version %d", path, magic[len(snapshotMagic)])
+	}
+	if err = DecodeSnapshot(gob.NewDecoder(reader), header, sim); err != nil {
+		return fmt.Errorf("snapshot: %s: %w", path, err)
+	}
+	return nil
+}
+
+// DecodeSnapshot membaca header dan state yang ditulis EncodeSnapshot
+func DecodeSnapshot(dec *gob.Decoder, header SnapshotHeader, sim Simulator) error {
+	snapshotter, ok := sim.(Snapshotter)
+	if !ok {
+		return fmt.Errorf("%T does not support snapshots", sim)
+	}
+	var saved SnapshotHeader
+	if err := dec.Decode(&saved); err != nil {
+		return unexpectedEOF(err)
+	}
+	if err := header.check(saved); err != nil {
+		return err
+	}
+	return unexpectedEOF(snapshotter.Restore(dec))
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
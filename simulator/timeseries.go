@@ -0,0 +1,13 @@
+package simulator
+
+// Metric adalah satu nilai pada keluaran time series
+type Metric struct {
+	Name  string
+	Value float64
+}
+
+// Sampler diimplementasikan simulator yang bisa melaporkan kondisi
+// internalnya secara berkala (misalnya bobot expert pada LeCaR)
+type Sampler interface {
+	Sample() []Metric
+}
@@ -0,0 +1,42 @@
+// Package synthetic membangkitkan trace sintetis di memori untuk subcommand
+// bench dan benchmark/test algoritma
+package synthetic
+
+import (
+	"fmt"
+	"math/rand"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Workloads adalah nama trace sintetis yang dikenali Trace
+var Workloads = []string{"uniform", "zipf", "scan"}
+
+// Trace membangkitkan requests request dengan footprint alamat berbeda;
+// hasilnya sama untuk seed yang sama
+func Trace(workload string, requests, footprint int, writeRatio float64, seed int64) (traces []simulator.Trace, err error) {
+	rng := rand.New(rand.NewSource(seed))
+	var next func(i int) int
+	switch workload {
+	case "uniform":
+		next = func(int) int { return rng.Intn(footprint) }
+	case "zipf":
+		zipf := rand.NewZipf(rng, 1.1, 1, uint64(footprint-1))
+		next = func(int) int { return int(zipf.Uint64()) }
+	case "scan":
+		// loop berurutan, kasus terburuk untuk LRU
+		next = func(i int) int { return i % footprint }
+	default:
+		return nil, fmt.Errorf("workload %q not supported", workload)
+	}
+
+	traces = make([]simulator.Trace, requests)
+	for i := range traces {
+		traces[i].Addr = next(i)
+		traces[i].Op = "R"
+		if rng.Float64() < writeRatio {
+			traces[i].Op = "W"
+		}
+	}
+	return traces, nil
+}
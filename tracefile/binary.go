@@ -0,0 +1,350 @@
+package tracefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Format biner:
+//
+//	header  : magic "WECTRACE", versi (1 byte), flag (1 byte)
+//	record  : tag (1 byte) | delta alamat (varint) | [delta timestamp (varint)] | [ukuran (uvarint)]
+//	trailer : tag 0 | jumlah record (8 byte LE) | CRC32 IEEE (4 byte LE)
+//
+// CRC32 dihitung dari seluruh byte record sampai dan termasuk tag 0.
+//
+// Tag record adalah kode op; op selain R/W disimpan sebagai tag tagOther
+// diikuti panjang (uvarint, paling besar maxOpLength) dan isi string op.
+const (
+	binaryMagic   = "WECTRACE"
+	binaryVersion = 1
+
+	flagTime = 1 << 0
+	flagSize = 1 << 1
+
+	tagEnd = 0
+
+	// jumlah record dan CRC32 setelah tag akhir
+	trailerSize = 8 + 4
+	tagRead     = 1
+	tagWrite    = 2
+	tagOther    = 3
+
+	// panjang op tagOther dibatasi agar file rusak tidak memicu alokasi
+	// sebesar nilai panjang yang terbaca
+	maxOpLength = 255
+)
+
+var ErrChecksum = errors.New("tracefile: checksum mismatch")
+
+// BinaryWriter menulis trace biner, Close wajib dipanggil untuk menulis
+// trailer
+type BinaryWriter struct {
+	writer  *bufio.Writer
+	crc     hash.Hash32
+	hasTime bool
+	hasSize bool
+
+	count    uint64
+	lastAddr int
+	lastTime int64
+	buf      []byte
+}
+
+func NewBinaryWriter(w io.Writer, hasTime, hasSize bool) (*BinaryWriter, error) {
+	writer := &BinaryWriter{
+		writer:  bufio.NewWriterSize(w, 1<<16),
+		crc:     crc32.NewIEEE(),
+		hasTime: hasTime,
+		hasSize: hasSize,
+		buf:     make([]byte, 0, 3*binary.MaxVarintLen64),
+	}
+	var flags byte
+	if hasTime {
+		flags |= flagTime
+	}
+	if hasSize {
+		flags |= flagSize
+	}
+	header := append([]byte(binaryMagic), binaryVersion, flags)
+	if _, err := writer.writer.Write(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *BinaryWriter) Write(trace simulator.Trace) error {
+	buf := writer.buf[:0]
+	switch trace.Op {
+	case "R":
+		buf = append(buf, tagRead)
+	case "W":
+		buf = append(buf, tagWrite)
+	default:
+		if len(trace.Op) > maxOpLength {
+			return fmt.Errorf("tracefile: op of %d bytes is longer than %d", len(trace.Op), maxOpLength)
+		}
+		buf = append(buf, tagOther)
+		buf = binary.AppendUvarint(buf, uint64(len(trace.Op)))
+		buf = append(buf, trace.Op...)
+	}
+	buf = binary.AppendVarint(buf, int64(trace.Addr-writer.lastAddr))
+	writer.lastAddr = trace.Addr
+	if writer.hasTime {
+		buf = binary.AppendVarint(buf, trace.Time-writer.lastTime)
+		writer.lastTime = trace.Time
+	}
+	if writer.hasSize {
+		buf = binary.AppendUvarint(buf, uint64(trace.Size))
+	}
+	writer.buf = buf[:0]
+
+	writer.crc.Write(buf)
+	writer.count++
+	_, err := writer.writer.Write(buf)
+	return err
+}
+
+func (writer *BinaryWriter) Close() error {
+	writer.crc.Write([]byte{tagEnd})
+	trailer := binary.LittleEndian.AppendUint64([]byte{tagEnd}, writer.count)
+	trailer = binary.LittleEndian.AppendUint32(trailer, writer.crc.Sum32())
+	if _, err := writer.writer.Write(trailer); err != nil {
+		return err
+	}
+	return writer.writer.Flush()
+}
+
+// byteReader adalah sumber byte yang bisa dibaca decoder, baik buffer
+// streaming maupun data hasil mmap
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+type decoder struct {
+	reader   byteReader
+	hasTime  bool
+	hasSize  bool
+	count    uint64
+	lastAddr int
+	lastTime int64
+	done     bool
+}
+
+func newDecoder(reader byteReader) (*decoder, error) {
+	header := make([]byte, len(binaryMagic)+2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("tracefile: header: %w", err)
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return nil, errors.New("tracefile: not a binary trace")
+	}
+	if version := header[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("tracefile: unsupported version %d", version)
+	}
+	flags := header[len(binaryMagic)+1]
+	return &decoder{
+		reader:  reader,
+		hasTime: flags&flagTime != 0,
+		hasSize: flags&flagSize != 0,
+	}, nil
+}
+
+// next mengembalikan io.EOF saat trailer tercapai; jumlah dan checksum
+// trailer diperiksa oleh pemanggil lewat trailer
+func (d *decoder) next() (trace simulator.Trace, err error) {
+	if d.done {
+		return trace, io.EOF
+	}
+	tag, err := d.reader.ReadByte()
+	if err != nil {
+		return trace, unexpected(err)
+	}
+	switch tag {
+	case tagEnd:
+		d.done = true
+		return trace, io.EOF
+	case tagRead:
+		trace.Op = "R"
+	case tagWrite:
+		trace.Op = "W"
+	case tagOther:
+		length, err := binary.ReadUvarint(d.reader)
+		if err != nil {
+			return trace, unexpected(err)
+		}
+		if length > maxOpLength {
+			return trace, fmt.Errorf("tracefile: op length %d exceeds %d at record %d", length, maxOpLength, d.count)
+		}
+		op := make([]byte, length)
+		if _, err := io.ReadFull(d.reader, op); err != nil {
+			return trace, unexpected(err)
+		}
+		trace.Op = string(op)
+	default:
+		return trace, fmt.Errorf("tracefile: invalid record tag %d at record %d", tag, d.count)
+	}
+
+	delta, err := binary.ReadVarint(d.reader)
+	if err != nil {
+		return trace, unexpected(err)
+	}
+	d.lastAddr += int(delta)
+	trace.Addr = d.lastAddr
+	if d.hasTime {
+		delta, err := binary.ReadVarint(d.reader)
+		if err != nil {
+			return trace, unexpected(err)
+		}
+		d.lastTime += delta
+		trace.Time = d.lastTime
+	}
+	if d.hasSize {
+		size, err := binary.ReadUvarint(d.reader)
+		if err != nil {
+			return trace, unexpected(err)
+		}
+		trace.Size = int(size)
+	}
+	d.count++
+	return trace, nil
+}
+
+// trailer membaca jumlah record dan checksum setelah tag akhir
+func (d *decoder) trailer() (count uint64, checksum uint32, err error) {
+	trailer := make([]byte, trailerSize)
+	if _, err = io.ReadFull(d.reader, trailer); err != nil {
+		return 0, 0, unexpected(err)
+	}
+	count = binary.LittleEndian.Uint64(trailer)
+	if count != d.count {
+		return 0, 0, fmt.Errorf("tracefile: trailer has %d records, read %d", count, d.count)
+	}
+	return count, binary.LittleEndian.Uint32(trailer[8:]), nil
+}
+
+func unexpected(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("tracefile: truncated binary trace: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// crcReader menghitung CRC32 dari setiap byte yang dibaca decoder
+type crcReader struct {
+	reader  *bufio.Reader
+	crc     uint32
+	enabled bool
+}
+
+func (r *crcReader) ReadByte() (byte, error) {
+	b, err := r.reader.ReadByte()
+	if err == nil && r.enabled {
+		r.crc = crc32.Update(r.crc, crc32.IEEETable, []byte{b})
+	}
+	return b, err
+}
+
+func (r *crcReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if r.enabled {
+		r.crc = crc32.Update(r.crc, crc32.IEEETable, p[:n])
+	}
+	return n, err
+}
+
+// BinaryReader membaca trace biner secara streaming dan memeriksa
+// checksum saat trailer tercapai
+type BinaryReader struct {
+	source  *crcReader
+	decoder *decoder
+}
+
+func NewBinaryReader(r *bufio.Reader) (*BinaryReader, error) {
+	source := &crcReader{reader: r}
+	d, err := newDecoder(source)
+	if err != nil {
+		return nil, err
+	}
+	source.enabled = true
+	return &BinaryReader{source: source, decoder: d}, nil
+}
+
+func (reader *BinaryReader) Read() (trace simulator.Trace, err error) {
+	if reader.decoder.done {
+		return trace, io.EOF
+	}
+	trace, err = reader.decoder.next()
+	if err != io.EOF {
+		return trace, err
+	}
+	reader.source.enabled = false
+	_, checksum, err := reader.decoder.trailer()
+	if err != nil {
+		return trace, err
+	}
+	if checksum != reader.source.crc {
+		return trace, ErrChecksum
+	}
+	return trace, io.EOF
+}
+
+func (reader *BinaryReader) HasTime() bool {
+	return reader.decoder.hasTime
+}
+
+func (reader *BinaryReader) HasSize() bool {
+	return reader.decoder.hasSize
+}
+
+// Stats trace biner hanya berisi jumlah record, validasi sudah dilakukan
+// saat convert
+func (reader *BinaryReader) Stats() Stats {
+	return Stats{Records: int(reader.decoder.count)}
+}
+
+// decodeBinary memecah seluruh isi file biner yang sudah berada di memori
+func decodeBinary(data []byte) (traces []simulator.Trace, err error) {
+	source := bytes.NewReader(data)
+	d, err := newDecoder(source)
+	if err != nil {
+		return traces, err
+	}
+	start := len(data) - source.Len()
+	if len(data)-start >= trailerSize {
+		// jumlah record di trailer dipakai untuk alokasi sekali saja, nilainya
+		// tetap divalidasi oleh trailer setelah decode
+		count := binary.LittleEndian.Uint64(data[len(data)-trailerSize:])
+		if count <= uint64(len(data)) {
+			traces = make([]simulator.Trace, 0, count)
+		}
+	}
+	for {
+		trace, err := d.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return traces, err
+		}
+		traces = append(traces, trace)
+	}
+	end := len(data) - source.Len()
+	_, checksum, err := d.trailer()
+	if err != nil {
+		return traces, err
+	}
+	if checksum != crc32.ChecksumIEEE(data[start:end]) {
+		return traces, ErrChecksum
+	}
+	return traces, nil
+}
@@ -0,0 +1,64 @@
+package tracefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// TestOpLength memastikan panjang op dari file rusak dibatasi sebelum
+// alokasi, baik saat streaming maupun decode di memori
+func TestOpLength(t *testing.T) {
+	data := append([]byte(binaryMagic), binaryVersion, 0, tagOther)
+	data = binary.AppendUvarint(data, 1<<62)
+	for len(data) < 21 {
+		data = append(data, 0)
+	}
+
+	if _, err := decodeBinary(data); err == nil || !strings.Contains(err.Error(), "op length") {
+		t.Errorf("decodeBinary: %v", err)
+	}
+	reader, err := NewBinaryReader(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Read(); err == nil || !strings.Contains(err.Error(), "op length") {
+		t.Errorf("Read: %v", err)
+	}
+}
+
+func TestOtherOpRoundTrip(t *testing.T) {
+	traces := []simulator.Trace{{Addr: 7, Op: "R"}, {Addr: 3, Op: strings.Repeat("X", maxOpLength)}, {Addr: 9, Op: "W"}}
+	var buf bytes.Buffer
+	writer, err := NewBinaryWriter(&buf, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trace := range traces {
+		if err := writer.Write(trace); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Write(simulator.Trace{Op: strings.Repeat("X", maxOpLength+1)}); err == nil {
+		t.Error("op longer than maxOpLength written")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(traces) {
+		t.Fatalf("decoded %d records, wrote %d", len(got), len(traces))
+	}
+	for i := range traces {
+		if got[i] != traces[i] {
+			t.Errorf("record %d: %+v, wrote %+v", i, got[i], traces[i])
+		}
+	}
+}
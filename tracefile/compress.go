@@ -0,0 +1,138 @@
+package tracefile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+const (
+	CompressionNone  = "none"
+	CompressionGzip  = "gzip"
+	CompressionBzip2 = "bzip2"
+	CompressionZstd  = "zstd"
+	CompressionXz    = "xz"
+	CompressionLz4   = "lz4"
+)
+
+type compression struct {
+	name  string
+	magic []byte
+	// command eksternal untuk format yang tidak ada di standard library
+	command string
+}
+
+var compressions = []compression{
+	{name: CompressionGzip, magic: []byte{0x1f, 0x8b}},
+	{name: CompressionBzip2, magic: []byte("BZh")},
+	{name: CompressionZstd, magic: []byte{0x28, 0xb5, 0x2f, 0xfd}, command: "zstd"},
+	{name: CompressionXz, magic: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, command: "xz"},
+	{name: CompressionLz4, magic: []byte{0x04, 0x22, 0x4d, 0x18}, command: "lz4"},
+}
+
+func detectCompression(header []byte) (compression, bool) {
+	for _, c := range compressions {
+		if bytes.HasPrefix(header, c.magic) {
+			return c, true
+		}
+	}
+	return compression{name: CompressionNone}, false
+}
+
+func isCompressed(data []byte) bool {
+	_, ok := detectCompression(data)
+	return ok
+}
+
+// decompress membungkus r dengan dekompresor sesuai magic di awal data;
+// close menunggu proses eksternal dan melaporkan kegagalannya
+func decompress(r *bufio.Reader) (reader io.Reader, name string, close func() error, err error) {
+	header, _ := r.Peek(8)
+	c, ok := detectCompression(header)
+	if !ok {
+		return r, CompressionNone, func() error { return nil }, nil
+	}
+
+	switch c.name {
+	case CompressionGzip:
+		// gzip.Reader membaca multistream (hasil cat beberapa .gz) secara default
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, c.name, nil, fmt.Errorf("tracefile: gzip: %w", err)
+		}
+		return gz, c.name, gz.Close, nil
+	case CompressionBzip2:
+		return bzip2.NewReader(r), c.name, func() error { return nil }, nil
+	}
+	return decompressCommand(r, c)
+}
+
+func decompressCommand(r io.Reader, c compression) (reader io.Reader, name string, close func() error, err error) {
+	path, err := exec.LookPath(c.command)
+	if err != nil {
+		return nil, c.name, nil, fmt.Errorf("tracefile: %s-compressed trace needs the %q command in PATH", c.name, c.command)
+	}
+	command := &commandReader{cmd: exec.Command(path, "-d", "-c")}
+	command.cmd.Stdin = r
+	command.cmd.Stderr = &command.stderr
+	command.stdout, err = command.cmd.StdoutPipe()
+	if err != nil {
+		return nil, c.name, nil, err
+	}
+	if err = command.cmd.Start(); err != nil {
+		return nil, c.name, nil, err
+	}
+	return command, c.name, command.Close, nil
+}
+
+// commandReader membaca output proses dekompresi eksternal; kegagalan
+// proses dilaporkan saat output habis, bukan hanya io.EOF yang terlihat
+// seperti trace yang lengkap
+type commandReader struct {
+	cmd    *exec.Cmd
+	stdout io.Reader
+	stderr strings.Builder
+
+	waited bool
+	err    error
+}
+
+func (r *commandReader) Read(p []byte) (int, error) {
+	if r.waited {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	n, err := r.stdout.Read(p)
+	if err == io.EOF {
+		if waitErr := r.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *commandReader) wait() error {
+	r.waited = true
+	if err := r.cmd.Wait(); err != nil {
+		r.err = fmt.Errorf("tracefile: %s: %v: %s", r.cmd.Args[0], err, strings.TrimSpace(r.stderr.String()))
+	}
+	return r.err
+}
+
+// Close menghentikan proses bila output belum habis dibaca
+func (r *commandReader) Close() error {
+	if r.waited {
+		return r.err
+	}
+	r.cmd.Process.Kill()
+	r.waited = true
+	r.cmd.Wait()
+	return nil
+}
@@ -0,0 +1,266 @@
+package tracefile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+// Options mengatur validasi trace CSV
+type Options struct {
+	// Lenient melewati dan menghitung baris rusak alih-alih berhenti di
+	// baris rusak pertama
+	Lenient bool
+	// OpMap memetakan op tambahan ke R atau W, di luar alias bawaan
+	OpMap map[string]string
+}
+
+// alias op bawaan, dicocokkan tanpa membedakan huruf besar/kecil
+var defaultOps = map[string]string{
+	"r":     "R",
+	"read":  "R",
+	"w":     "W",
+	"write": "W",
+}
+
+// ParseOpMap membaca pemetaan op berbentuk "asal=R,asal=W"
+func ParseOpMap(s string) (map[string]string, error) {
+	ops := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return ops, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		from, to, ok := strings.Cut(pair, "=")
+		from, to = strings.TrimSpace(from), strings.ToUpper(strings.TrimSpace(to))
+		if !ok || from == "" || (to != "R" && to != "W") {
+			return nil, fmt.Errorf("tracefile: invalid op mapping %q, expected op=R or op=W", pair)
+		}
+		ops[strings.ToLower(from)] = to
+	}
+	return ops, nil
+}
+
+// maxDiagnostics membatasi jumlah baris rusak yang disimpan di Stats
+const maxDiagnostics = 10
+
+// Stats mencatat record yang terbaca dan baris yang dilewati
+type Stats struct {
+	Records  int
+	Blank    int
+	Comments int
+	Headers  int
+	// Malformed dan UnknownOp hanya bertambah pada mode lenient
+	Malformed int
+	UnknownOp int
+	// Errors berisi diagnosa baris rusak pertama
+	Errors []*ParseError
+}
+
+// Skipped adalah jumlah baris data yang dibuang karena rusak
+func (stats Stats) Skipped() int {
+	return stats.Malformed + stats.UnknownOp
+}
+
+// PrintToFile menulis ringkasan pembacaan trace beserta diagnosa baris
+// yang dilewati
+func (stats Stats) PrintToFile(file *os.File, path string) (err error) {
+	result := fmt.Sprintf(`trace : %v
+trace records : %v
+trace skipped : %v (malformed %v, unknown op %v)
+trace ignored : blank %v, comment %v, header %v
+`,
+		displayPath(path),
+		stats.Records,
+		stats.Skipped(),
+		stats.Malformed,
+		stats.UnknownOp,
+		stats.Blank,
+		stats.Comments,
+		stats.Headers,
+	)
+	for _, parseErr := range stats.Errors {
+		result += fmt.Sprintf("trace skip : %v\n", parseErr)
+	}
+	if more := stats.Skipped() - len(stats.Errors); more > 0 {
+		result += fmt.Sprintf("trace skip : ... %v more\n", more)
+	}
+	result += fmt.Sprintf("!TRACE|%v|%v|%v|%v\n", stats.Records, stats.Skipped(), stats.Malformed, stats.UnknownOp)
+	_, err = file.WriteString(result)
+	return err
+}
+
+// ErrUnknownOp dikembalikan untuk op selain R/W yang tidak ada di pemetaan
+var ErrUnknownOp = errors.New("unknown op")
+
+// ParseError menunjuk baris trace yang rusak beserta isinya
+type ParseError struct {
+	Path    string
+	Line    int
+	Content string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	content := e.Content
+	if len(content) > 80 {
+		content = content[:80] + "..."
+	}
+	return fmt.Sprintf("%s:%d: %v: %q", e.Path, e.Line, e.Err, content)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// CSVReader membaca baris alamat,op[,timestamp[,ukuran]]; baris kosong,
+// komentar (#) dan header di baris data pertama dilewati
+type CSVReader struct {
+	scanner *bufio.Scanner
+	path    string
+	options Options
+	line    int
+	stats   Stats
+
+	// ditentukan dari baris data pertama
+	columns int
+}
+
+func NewCSVReader(r io.Reader, path string, options Options) *CSVReader {
+	return &CSVReader{scanner: bufio.NewScanner(r), path: path, options: options}
+}
+
+func (reader *CSVReader) Read() (trace simulator.Trace, err error) {
+	for reader.scanner.Scan() {
+		reader.line++
+		line := strings.TrimSpace(reader.scanner.Text())
+		switch {
+		case line == "":
+			reader.stats.Blank++
+			continue
+		case strings.HasPrefix(line, "#"):
+			reader.stats.Comments++
+			continue
+		}
+
+		trace, err = reader.parse(line)
+		if err == nil {
+			reader.stats.Records++
+			return trace, nil
+		}
+		parseErr := &ParseError{Path: reader.path, Line: reader.line, Content: line, Err: err}
+		if reader.columns == 0 && reader.stats.Headers == 0 && isHeader(line) {
+			reader.stats.Headers++
+			continue
+		}
+		if !reader.options.Lenient {
+			// baris terpotong di akhir trace terkompresi yang rusak; kesalahan
+			// dekompresi lebih berguna daripada isi baris terakhir
+			if !reader.scanner.Scan() && reader.scanner.Err() != nil {
+				return trace, reader.readError()
+			}
+			return trace, parseErr
+		}
+		if errors.Is(err, ErrUnknownOp) {
+			reader.stats.UnknownOp++
+		} else {
+			reader.stats.Malformed++
+		}
+		if len(reader.stats.Errors) < maxDiagnostics {
+			reader.stats.Errors = append(reader.stats.Errors, parseErr)
+		}
+	}
+	if err = reader.readError(); err != nil {
+		return trace, err
+	}
+	return trace, io.EOF
+}
+
+func (reader *CSVReader) readError() error {
+	if err := reader.scanner.Err(); err != nil {
+		return fmt.Errorf("%s:%d: %w", reader.path, reader.line+1, err)
+	}
+	return nil
+}
+
+func (reader *CSVReader) parse(line string) (trace simulator.Trace, err error) {
+	row := strings.Split(line, ",")
+	if len(row) < 2 {
+		return trace, errors.New("missing op column")
+	}
+	trace.Addr, err = strconv.Atoi(strings.TrimSpace(row[0]))
+	if err != nil {
+		return trace, fmt.Errorf("address: %w", numError(err))
+	}
+	if trace.Addr < 0 {
+		return trace, errors.New("address: negative")
+	}
+	trace.Op, err = reader.op(strings.TrimSpace(row[1]))
+	if err != nil {
+		return trace, err
+	}
+	if len(row) > 2 {
+		trace.Time, err = strconv.ParseInt(strings.TrimSpace(row[2]), 10, 64)
+		if err != nil {
+			return trace, fmt.Errorf("timestamp: %w", numError(err))
+		}
+	}
+	if len(row) > 3 {
+		trace.Size, err = strconv.Atoi(strings.TrimSpace(row[3]))
+		if err != nil {
+			return trace, fmt.Errorf("size: %w", numError(err))
+		}
+	}
+	if reader.columns == 0 {
+		reader.columns = len(row)
+	}
+	return trace, nil
+}
+
+func (reader *CSVReader) op(op string) (string, error) {
+	if op == "R" || op == "W" {
+		return op, nil
+	}
+	key := strings.ToLower(op)
+	if mapped, ok := reader.options.OpMap[key]; ok {
+		return mapped, nil
+	}
+	if mapped, ok := defaultOps[key]; ok {
+		return mapped, nil
+	}
+	return "", ErrUnknownOp
+}
+
+// isHeader menganggap baris sebagai header bila kolom alamatnya bukan angka
+func isHeader(line string) bool {
+	field, _, _ := strings.Cut(line, ",")
+	_, err := strconv.Atoi(strings.TrimSpace(field))
+	return err != nil
+}
+
+// numError membuang nama fungsi strconv dari pesan kesalahan
+func numError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+// HasTime dan HasSize hanya valid setelah record pertama terbaca
+func (reader *CSVReader) HasTime() bool {
+	return reader.columns > 2
+}
+
+func (reader *CSVReader) HasSize() bool {
+	return reader.columns > 3
+}
+
+func (reader *CSVReader) Stats() Stats {
+	return reader.stats
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package tracefile
+
+import "os"
+
+func mapFile(path string) (data []byte, release func() error, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package tracefile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mapFile memetakan file ke memori read-only; release wajib dipanggil
+// setelah data tidak dipakai lagi
+func mapFile(path string) (data []byte, release func() error, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		return nil, nil, errors.New("tracefile: cannot mmap")
+	}
+	data, err = syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
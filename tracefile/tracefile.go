@@ -0,0 +1,146 @@
+// Package tracefile membaca trace request dalam format CSV
+// (alamat,op[,timestamp[,ukuran]]) maupun format biner hasil subcommand
+// convert; format dan kompresi (gzip, bzip2, zstd, xz, lz4) dideteksi
+// otomatis dari magic di awal file. Path "-" membaca dari stdin.
+package tracefile
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+
+	"ixtza/ajk/wec/simulator"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatBinary = "binary"
+)
+
+// Reader mengembalikan request satu per satu, io.EOF di akhir trace
+type Reader interface {
+	Read() (simulator.Trace, error)
+	HasTime() bool
+	HasSize() bool
+	Stats() Stats
+}
+
+// Stdin adalah path yang berarti trace dibaca dari standard input
+const Stdin = "-"
+
+// File adalah trace yang dibuka dari disk atau stdin
+type File struct {
+	Reader
+	Format      string
+	Compression string
+
+	file       *os.File
+	decompress func() error
+}
+
+// Open membuka trace secara streaming lewat buffer
+func Open(path string, options Options) (*File, error) {
+	if path == Stdin {
+		return newFile(os.Stdin, displayPath(path), options)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	traceFile, err := newFile(file, path, options)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	traceFile.file = file
+	return traceFile, nil
+}
+
+// displayPath adalah nama trace pada diagnosa baris rusak
+func displayPath(path string) string {
+	if path == Stdin {
+		return "<stdin>"
+	}
+	return path
+}
+
+func newFile(r io.Reader, path string, options Options) (*File, error) {
+	reader, compression, closeDecompress, err := decompress(bufio.NewReaderSize(r, 1<<16))
+	if err != nil {
+		return nil, err
+	}
+	traceFile := &File{Compression: compression, decompress: closeDecompress}
+
+	buffered, ok := reader.(*bufio.Reader)
+	if !ok {
+		buffered = bufio.NewReaderSize(reader, 1<<16)
+	}
+	magic, _ := buffered.Peek(len(binaryMagic))
+	if bytes.Equal(magic, []byte(binaryMagic)) {
+		binaryReader, err := NewBinaryReader(buffered)
+		if err != nil {
+			closeDecompress()
+			return nil, err
+		}
+		traceFile.Reader, traceFile.Format = binaryReader, FormatBinary
+		return traceFile, nil
+	}
+	traceFile.Reader, traceFile.Format = NewCSVReader(buffered, path, options), FormatCSV
+	return traceFile, nil
+}
+
+func (f *File) Close() error {
+	err := f.decompress()
+	if f.file != nil {
+		if closeErr := f.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ReadAll memuat seluruh trace ke memori; file biasa dibaca lewat mmap
+// bila tersedia sehingga jumlah record bisa diketahui sebelum parsing
+func ReadAll(path string, options Options) (traces []simulator.Trace, stats Stats, err error) {
+	var data []byte
+	var release func() error
+	if path != Stdin {
+		data, release, err = mapFile(path)
+	}
+	if path == Stdin || err != nil || isCompressed(data) {
+		if release != nil {
+			release()
+		}
+		file, err := Open(path, options)
+		if err != nil {
+			return traces, stats, err
+		}
+		traces, stats, err = readAll(file, 0)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return traces, stats, err
+	}
+	defer release()
+
+	if bytes.HasPrefix(data, []byte(binaryMagic)) {
+		traces, err = decodeBinary(data)
+		return traces, Stats{Records: len(traces)}, err
+	}
+	return readAll(NewCSVReader(bytes.NewReader(data), path, options), bytes.Count(data, []byte{'\n'})+1)
+}
+
+func readAll(reader Reader, capacity int) (traces []simulator.Trace, stats Stats, err error) {
+	traces = make([]simulator.Trace, 0, capacity)
+	for {
+		trace, err := reader.Read()
+		if err == io.EOF {
+			return traces, reader.Stats(), nil
+		}
+		if err != nil {
+			return traces, reader.Stats(), err
+		}
+		traces = append(traces, trace)
+	}
+}